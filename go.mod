@@ -3,3 +3,9 @@ module cli_ollama_server
 go 1.22
 
 require github.com/pelletier/go-toml/v2 v2.2.3
+
+require (
+	golang.org/x/crypto v0.33.0
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/term v0.29.0
+)
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
 	"cli_ollama_server/internal/config"
 	"cli_ollama_server/internal/execollama"
 	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
 	"cli_ollama_server/internal/ollamarunner"
 )
 
@@ -20,6 +22,7 @@ type globalOpts struct {
 	Mode      string
 	Unsafe    *bool
 	Config    string
+	Trace     string
 	Help      bool
 	Version   bool
 }
@@ -120,10 +123,47 @@ func Run(args []string) int {
 	} else {
 		eff.Mode = m
 	}
-	if _, herr := config.ParseHostURL(eff.Host); herr != nil {
-		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_host", "host", eff.Host, "error", herr.Error()))
+	if host, herr := config.CheckHosts(eff.Hosts); herr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_host", "host", host, "error", herr.Error()))
 		return 2
 	}
+	if b, berr := config.NormalizeBalance(eff.Balance); berr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_balance", "balance", eff.Balance))
+		return 2
+	} else {
+		eff.Balance = b
+	}
+	if herr := config.ValidateHeaders(eff.Headers); herr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_headers", "error", eff.Redact(herr.Error())))
+		return 2
+	}
+	tlsCfg, terr := eff.TLSConfig()
+	if terr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_tls", "error", terr.Error()))
+		return 2
+	}
+	proxyURL, perr := eff.ProxyURL()
+	if perr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_proxy", "error", perr.Error()))
+		return 2
+	}
+	timeouts, toerr := eff.Timeouts()
+	if toerr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_timeout", "error", toerr.Error()))
+		return 2
+	}
+	sshCfg, serr := eff.SSHConfig()
+	if serr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_ssh", "error", serr.Error()))
+		return 2
+	}
+	warnInsecureTLS(tr, eff)
+	if b, berr := config.NormalizeBackend(eff.Backend); berr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_backend", "backend", eff.Backend))
+		return 2
+	} else {
+		eff.Backend = b
+	}
 	if eff.Mode != "native" && strings.TrimSpace(eff.OllamaExe) != "" {
 		if _, err := execollama.ResolveExecutable(eff.OllamaExe); err != nil {
 			fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_ollama_exe", "path", eff.OllamaExe))
@@ -142,12 +182,33 @@ func Run(args []string) int {
 		return 2
 	}
 
-	code, runErr := ollamarunner.Run(context.Background(), ollamarunner.Options{
+	ctx := context.Background()
+	tracer := newTracer(opts, eff)
+	if tracer != nil {
+		// Let Ctrl+C end the command through its context so the request in
+		// flight is recorded before the process exits.
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		defer closeTracer(tr, tracer)
+	}
+
+	code, runErr := ollamarunner.Run(ctx, ollamarunner.Options{
 		Mode:        eff.Mode,
 		Host:        eff.Host,
 		OllamaExe:   eff.OllamaExe,
 		NoProxyAuto: eff.NoProxyAuto,
 		Unsafe:      eff.Unsafe,
+		Hosts:       eff.Hosts,
+		Balance:     eff.Balance,
+		Headers:     eff.RequestHeaders(),
+		TLS:         tlsCfg,
+		Proxy:       proxyURL,
+		NoProxy:     eff.NoProxy,
+		SSH:         ollamaapi.SSHConfig(sshCfg),
+		Timeouts:    ollamaapi.Timeouts(timeouts),
+		Backend:     eff.Backend,
+		Trace:       tracer,
 		Env:         env,
 		Args:        rest,
 		Stdout:      os.Stdout,
@@ -158,7 +219,9 @@ func Run(args []string) int {
 	if runErr != nil {
 		selected := eff.Mode
 		if selected == "auto" {
-			if _, err := execollama.ResolveExecutable(eff.OllamaExe); err == nil {
+			if eff.Backend != "ollama" || ollamarunner.NativeOnly(rest) {
+				selected = "native"
+			} else if _, err := execollama.ResolveExecutable(eff.OllamaExe); err == nil {
 				selected = "wrapper"
 			} else {
 				selected = "native"
@@ -173,16 +236,48 @@ func Run(args []string) int {
 			// Wrapper mode errors are usually already printed by the upstream CLI.
 			// Native mode returns rich errors that would otherwise be silent.
 			if selected == "native" {
-				fmt.Fprintln(os.Stderr, runErr.Error())
+				fmt.Fprintln(os.Stderr, eff.Redact(runErr.Error()))
 			}
 			return code
 		}
-		fmt.Fprintln(os.Stderr, tr.Sprintf("error.ollama_failed", "error", runErr.Error()))
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.ollama_failed", "error", eff.Redact(runErr.Error())))
 		return 1
 	}
 	return code
 }
 
+// newTracer returns the --trace recorder, or nil without the flag. Configured
+// header values are redacted in addition to Authorization.
+func newTracer(opts globalOpts, eff config.Effective) *ollamaapi.Tracer {
+	if strings.TrimSpace(opts.Trace) == "" {
+		return nil
+	}
+	return ollamaapi.NewTracer(opts.Trace, version, eff.HeaderNames()...)
+}
+
+func closeTracer(tr *i18n.Bundle, t *ollamaapi.Tracer) {
+	if err := t.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.trace_write", "error", err.Error()))
+	}
+}
+
+// warnInsecureTLS prints a warning to stderr when certificate verification is
+// disabled, once for every https host.
+func warnInsecureTLS(tr *i18n.Bundle, eff config.Effective) {
+	if !eff.TLSInsecureSkipVerify {
+		return
+	}
+	hosts := eff.Hosts
+	if len(hosts) == 0 {
+		hosts = []config.HostEntry{{URL: eff.Host}}
+	}
+	for _, h := range hosts {
+		if u, err := config.ParseHostURL(h.URL); err == nil && u.Scheme == "https" {
+			fmt.Fprintln(os.Stderr, tr.Sprintf("warning.tls_insecure", "host", u.Host))
+		}
+	}
+}
+
 func parseGlobal(args []string) (globalOpts, []string, error) {
 	var out globalOpts
 	rest := make([]string, 0, len(args))
@@ -253,6 +348,14 @@ func parseGlobal(args []string) (globalOpts, []string, error) {
 			i++
 		case strings.HasPrefix(a, "--config="):
 			out.Config = strings.TrimPrefix(a, "--config=")
+		case a == "--trace":
+			if i+1 >= len(args) {
+				return out, nil, &argError{Kind: "missing_value", Flag: "--trace"}
+			}
+			out.Trace = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--trace="):
+			out.Trace = strings.TrimPrefix(a, "--trace=")
 		default:
 			return out, nil, &argError{Kind: "unknown_flag", Flag: a}
 		}
@@ -272,6 +375,7 @@ func printHelp(tr *i18n.Bundle) {
 	fmt.Println(tr.Sprintf("help.flag.mode"))
 	fmt.Println(tr.Sprintf("help.flag.unsafe"))
 	fmt.Println(tr.Sprintf("help.flag.config"))
+	fmt.Println(tr.Sprintf("help.flag.trace"))
 	fmt.Println(tr.Sprintf("help.flag.help"))
 	fmt.Println(tr.Sprintf("help.flag.version"))
 	fmt.Println()
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"cli_ollama_server/internal/config"
@@ -24,7 +25,7 @@ func runConfig(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, args
 	case "show":
 		eff, _ := config.ResolveEffective(config.EffectiveOptions{LoadedConfig: loaded})
 		fmt.Println(tr.Sprintf("config.path", "path", meta.PrimaryPath))
-		fmt.Println(tr.Sprintf("config.host", "value", eff.Host))
+		fmt.Println(tr.Sprintf("config.host", "value", eff.HostList()))
 		langVal := eff.Lang
 		if strings.TrimSpace(langVal) == "" {
 			langVal = tr.Sprintf("config.value.auto")
@@ -42,6 +43,53 @@ func runConfig(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, args
 		fmt.Println(tr.Sprintf("config.mode", "value", modeVal))
 		fmt.Println(tr.Sprintf("config.no_proxy_auto", "value", fmtBool(eff.NoProxyAuto)))
 		fmt.Println(tr.Sprintf("config.unsafe", "value", fmtBool(eff.Unsafe)))
+		if eff.APIKey != "" {
+			fmt.Println(tr.Sprintf("config.api_key", "value", tr.Sprintf("config.value.redacted")))
+		}
+		for _, k := range sortedHeaderKeys(eff.Headers) {
+			fmt.Println(tr.Sprintf("config.header", "name", k, "value", tr.Sprintf("config.value.redacted")))
+		}
+		for _, kv := range [][2]string{
+			{"ca_file", eff.CAFile},
+			{"client_cert", eff.ClientCert},
+			{"client_key", eff.ClientKey},
+			{"tls_server_name", eff.TLSServerName},
+			{"ssh_identity_file", eff.SSHIdentityFile},
+			{"ssh_known_hosts_file", eff.SSHKnownHostsFile},
+		} {
+			if kv[1] != "" {
+				fmt.Println(tr.Sprintf("config.entry", "key", kv[0], "value", kv[1]))
+			}
+		}
+		if eff.TLSInsecureSkipVerify {
+			fmt.Println(tr.Sprintf("config.entry", "key", "tls_insecure_skip_verify", "value", "true"))
+		}
+		if eff.Backend != "ollama" {
+			fmt.Println(tr.Sprintf("config.entry", "key", "backend", "value", eff.Backend))
+		}
+		if len(eff.Hosts) > 1 {
+			fmt.Println(tr.Sprintf("config.entry", "key", "balance", "value", eff.Balance))
+		}
+		if eff.Proxy != "" {
+			// The proxy URL may carry credentials.
+			proxy := eff.Proxy
+			if u, err := config.ParseProxyURL(proxy); err == nil {
+				proxy = u.Redacted()
+			}
+			fmt.Println(tr.Sprintf("config.entry", "key", "proxy", "value", proxy))
+		}
+		if len(eff.NoProxy) > 0 {
+			fmt.Println(tr.Sprintf("config.entry", "key", "no_proxy", "value", strings.Join(eff.NoProxy, ",")))
+		}
+		for _, e := range [][2]string{
+			{"request_timeout", eff.RequestTimeout},
+			{"first_token_timeout", eff.FirstTokenTimeout},
+			{"stream_idle_timeout", eff.StreamIdleTimeout},
+		} {
+			if e[1] != "" {
+				fmt.Println(tr.Sprintf("config.entry", "key", e[0], "value", e[1]))
+			}
+		}
 		return 0
 	case "init":
 		if err := config.InitUserConfig(meta.PrimaryPath); err != nil {
@@ -79,3 +127,12 @@ func fmtBool(v bool) string {
 	}
 	return "false"
 }
+
+func sortedHeaderKeys(h map[string]string) []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
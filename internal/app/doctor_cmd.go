@@ -2,9 +2,13 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"cli_ollama_server/internal/config"
@@ -28,10 +32,50 @@ func runDoctor(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, opts
 	} else {
 		eff.Mode = m
 	}
-	if _, herr := config.ParseHostURL(eff.Host); herr != nil {
-		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_host", "host", eff.Host, "error", herr.Error()))
+	if host, herr := config.CheckHosts(eff.Hosts); herr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_host", "host", host, "error", herr.Error()))
 		return 2
 	}
+	if b, berr := config.NormalizeBalance(eff.Balance); berr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_balance", "balance", eff.Balance))
+		return 2
+	} else {
+		eff.Balance = b
+	}
+	if b, berr := config.NormalizeBackend(eff.Backend); berr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_backend", "backend", eff.Backend))
+		return 2
+	} else {
+		eff.Backend = b
+	}
+	openAI := eff.Backend == string(ollamaapi.BackendOpenAI)
+
+	if herr := config.ValidateHeaders(eff.Headers); herr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_headers", "error", eff.Redact(herr.Error())))
+		return 2
+	}
+
+	tlsCfg, terr := eff.TLSConfig()
+	if terr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_tls", "error", terr.Error()))
+		return 2
+	}
+	proxyURL, perr := eff.ProxyURL()
+	if perr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_proxy", "error", perr.Error()))
+		return 2
+	}
+	timeouts, toerr := eff.Timeouts()
+	if toerr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_timeout", "error", toerr.Error()))
+		return 2
+	}
+	sshCfg, serr := eff.SSHConfig()
+	if serr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_ssh", "error", serr.Error()))
+		return 2
+	}
+	warnInsecureTLS(tr, eff)
 
 	env, _, envErr := config.BuildChildEnv(config.ChildEnvOptions{Existing: os.Environ(), Effective: eff, LoadedMeta: meta})
 	if envErr != nil {
@@ -39,14 +83,31 @@ func runDoctor(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, opts
 		return 2
 	}
 
-	fmt.Println(tr.Sprintf("doctor.host", "value", eff.Host))
+	fmt.Println(tr.Sprintf("doctor.host", "value", eff.HostList()))
+	if u, _ := config.ParseHostURL(eff.Host); u != nil && (u.Scheme == "http" || u.Scheme == "https") && u.Path != "" {
+		fmt.Println(tr.Sprintf("doctor.base_path", "value", u.Path, "api", u.JoinPath("api").String()))
+	}
+	if u, _ := config.ParseHostURL(eff.Host); u != nil && u.Scheme == "ssh" {
+		server := *u
+		server.Path = ""
+		fmt.Println(tr.Sprintf("doctor.ssh_tunnel", "server", server.String(), "target", strings.TrimPrefix(u.Path, "/")))
+	}
 	fmt.Println(tr.Sprintf("doctor.lang", "value", tr.Lang()))
 	fmt.Println(tr.Sprintf("doctor.mode", "value", eff.Mode))
+	fmt.Println(tr.Sprintf("doctor.backend", "value", eff.Backend))
+	if len(eff.Hosts) > 1 {
+		fmt.Println(tr.Sprintf("doctor.balance", "value", eff.Balance))
+	}
 	fmt.Println(tr.Sprintf("doctor.unsafe", "value", fmtBool(eff.Unsafe)))
+	if names := eff.HeaderNames(); len(names) > 0 {
+		fmt.Println(tr.Sprintf("doctor.headers", "value", strings.Join(names, ", ")))
+	}
 
 	selected := eff.Mode
 	if selected == "auto" {
-		if _, err := execollama.ResolveExecutable(eff.OllamaExe); err == nil {
+		if openAI {
+			selected = "native"
+		} else if _, err := execollama.ResolveExecutable(eff.OllamaExe); err == nil {
 			selected = "wrapper"
 		} else {
 			selected = "native"
@@ -59,13 +120,60 @@ func runDoctor(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, opts
 
 	apiOK := false
 	if u, _ := config.ParseHostURL(eff.Host); u != nil {
-		v, verr := ollamaapi.NewClient(u, eff.NoProxyAuto).Version(ctx)
-		if verr != nil {
-			fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.api_version_failed", "error", verr.Error()))
+		copts := []ollamaapi.ClientOption{
+			ollamaapi.WithHeaders(eff.RequestHeaders()),
+			ollamaapi.WithTLSConfig(tlsCfg),
+			ollamaapi.WithBackend(ollamaapi.Backend(eff.Backend)),
+			ollamaapi.WithProxy(proxyURL, eff.NoProxy),
+			ollamaapi.WithSSHConfig(ollamaapi.SSHConfig(sshCfg)),
+			ollamaapi.WithTimeouts(ollamaapi.Timeouts(timeouts)),
+		}
+		if tracer := newTracer(opts, eff); tracer != nil {
+			copts = append(copts, ollamaapi.WithTracer(tracer))
+			defer closeTracer(tr, tracer)
+		}
+		if len(eff.Hosts) > 1 {
+			hosts := make([]ollamaapi.Host, 0, len(eff.Hosts))
+			for _, h := range eff.Hosts {
+				hu, _ := config.ParseHostURL(h.URL)
+				hosts = append(hosts, ollamaapi.Host{URL: hu, Weight: h.Weight})
+			}
+			copts = append(copts, ollamaapi.WithHosts(hosts...), ollamaapi.WithBalance(ollamaapi.Balance(eff.Balance)))
+		}
+		client := ollamaapi.NewClient(u, eff.NoProxyAuto, copts...)
+		defer client.Close()
+		reportProxy(tr, client)
+		if len(eff.Hosts) > 1 {
+			// Probing first also steers the checks below to a healthy host.
+			reportHosts(ctx, tr, eff, client)
+		}
+		if openAI {
+			// OpenAI-compatible servers have no version endpoint; listing
+			// models proves the API and the credentials work.
+			models, merr := client.Tags(ctx)
+			if merr != nil {
+				fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.api_models_failed", "error", eff.Redact(merr.Error())))
+			} else {
+				apiOK = true
+				fmt.Println(tr.Sprintf("doctor.api_models", "count", strconv.Itoa(len(models))))
+			}
+		} else if v, verr := client.Version(ctx); verr != nil {
+			fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.api_version_failed", "error", eff.Redact(verr.Error())))
 		} else {
 			apiOK = true
 			fmt.Println(tr.Sprintf("doctor.api_version", "value", v))
 		}
+		if u.Scheme == "https" {
+			reportTLS(ctx, tr, eff, u, client, tlsCfg, copts)
+		}
+	}
+
+	// The upstream CLI cannot talk to other backends, so it is not checked.
+	if openAI {
+		if !apiOK {
+			return 1
+		}
+		return 0
 	}
 
 	// Wrapper CLI detection (works in both modes).
@@ -108,3 +216,79 @@ func runDoctor(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, opts
 	}
 	return 0
 }
+
+// reportHosts prints the health of every host of a multi-host setup.
+func reportHosts(ctx context.Context, tr *i18n.Bundle, eff config.Effective, client *ollamaapi.Client) {
+	for _, h := range client.CheckHealth(ctx) {
+		weight := strconv.Itoa(h.Weight)
+		if h.Err != nil {
+			fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.host_down", "host", h.URL.Redacted(), "weight", weight, "error", eff.Redact(h.Err.Error())))
+			continue
+		}
+		fmt.Println(tr.Sprintf("doctor.host_ok", "host", h.URL.Redacted(), "weight", weight, "latency", h.Latency.Round(time.Millisecond).String()))
+	}
+}
+
+// reportProxy prints whether the host is reached through a proxy.
+func reportProxy(tr *i18n.Bundle, client *ollamaapi.Client) {
+	p, err := client.ProxyURL()
+	switch {
+	case err != nil:
+		fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.proxy", "value", err.Error()))
+	case p == nil:
+		fmt.Println(tr.Sprintf("doctor.proxy", "value", tr.Sprintf("doctor.value.proxy_direct")))
+	default:
+		fmt.Println(tr.Sprintf("doctor.proxy", "value", p.Redacted()))
+	}
+}
+
+// certExpiryWarning is how close to expiry a certificate is reported as a warning.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// reportTLS prints the negotiated TLS version and the certificate chain with
+// expiry dates. If verification fails, the chain is fetched once more without
+// verification so the user can see what the server actually presents. That
+// second client is built from copts, the options of the first, so it takes
+// the same path to the server.
+func reportTLS(ctx context.Context, tr *i18n.Bundle, eff config.Effective, u *url.URL, client *ollamaapi.Client, tlsCfg *tls.Config, copts []ollamaapi.ClientOption) {
+	state, err := client.ConnectionState(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.tls_failed", "error", eff.Redact(err.Error())))
+		insecure := &tls.Config{}
+		if tlsCfg != nil {
+			insecure = tlsCfg.Clone()
+		}
+		insecure.InsecureSkipVerify = true
+		fallback := ollamaapi.NewClient(u, eff.NoProxyAuto, append(copts[:len(copts):len(copts)], ollamaapi.WithTLSConfig(insecure))...)
+		defer fallback.Close()
+		state, err = fallback.ConnectionState(ctx)
+		if err != nil {
+			return
+		}
+	}
+	if state == nil {
+		return
+	}
+	verified := tr.Sprintf("doctor.value.tls_verified")
+	if len(state.VerifiedChains) == 0 {
+		verified = tr.Sprintf("doctor.value.tls_not_verified")
+	}
+	fmt.Println(tr.Sprintf("doctor.tls", "version", tls.VersionName(state.Version), "verified", verified))
+	now := time.Now()
+	for i, cert := range state.PeerCertificates {
+		left := cert.NotAfter.Sub(now)
+		fmt.Println(tr.Sprintf("doctor.tls_cert",
+			"index", strconv.Itoa(i),
+			"subject", cert.Subject.String(),
+			"issuer", cert.Issuer.String(),
+			"expires", cert.NotAfter.UTC().Format("2006-01-02"),
+			"days", strconv.Itoa(int(left.Hours()/24)),
+		))
+		switch {
+		case left <= 0:
+			fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.tls_cert_expired", "subject", cert.Subject.String(), "expires", cert.NotAfter.UTC().Format("2006-01-02")))
+		case left < certExpiryWarning:
+			fmt.Fprintln(os.Stderr, tr.Sprintf("doctor.tls_cert_expiring", "subject", cert.Subject.String(), "days", strconv.Itoa(int(left.Hours()/24))))
+		}
+	}
+}
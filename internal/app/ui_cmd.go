@@ -22,6 +22,32 @@ func runUI(tr *i18n.Bundle, loaded config.Config, meta config.LoadMeta, opts glo
 		LoadedConfig:        loaded,
 	})
 
+	warnInsecureTLS(tr, eff)
+	if _, perr := eff.ProxyURL(); perr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_proxy", "error", perr.Error()))
+		return 2
+	}
+	if _, toerr := eff.Timeouts(); toerr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_timeout", "error", toerr.Error()))
+		return 2
+	}
+	if _, serr := eff.SSHConfig(); serr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_ssh", "error", serr.Error()))
+		return 2
+	}
+	if b, berr := config.NormalizeBackend(eff.Backend); berr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_backend", "backend", eff.Backend))
+		return 2
+	} else {
+		eff.Backend = b
+	}
+	if b, berr := config.NormalizeBalance(eff.Balance); berr != nil {
+		fmt.Fprintln(os.Stderr, tr.Sprintf("error.invalid_balance", "balance", eff.Balance))
+		return 2
+	} else {
+		eff.Balance = b
+	}
+
 	listen := "127.0.0.1:0"
 	if len(args) >= 2 && args[0] == "--listen" {
 		listen = args[1]
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -8,15 +9,85 @@ import (
 	"strings"
 
 	toml "github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
 )
 
 type Config struct {
-	Host        string `toml:"host"`
-	Lang        string `toml:"lang"`
-	OllamaExe   string `toml:"ollama_exe"`
-	Mode        string `toml:"mode"`
-	NoProxyAuto *bool  `toml:"no_proxy_auto"`
-	Unsafe      *bool  `toml:"unsafe"`
+	Host        HostValue `toml:"host"`
+	Lang        string    `toml:"lang"`
+	OllamaExe   string    `toml:"ollama_exe"`
+	Mode        string    `toml:"mode"`
+	NoProxyAuto *bool     `toml:"no_proxy_auto"`
+	Unsafe      *bool     `toml:"unsafe"`
+	// APIKey is sent as "Authorization: Bearer <api_key>".
+	APIKey string `toml:"api_key,omitempty"`
+	// Headers are added to every API request (the [headers] table).
+	Headers map[string]string `toml:"headers,omitempty"`
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string `toml:"ca_file,omitempty"`
+	// ClientCert and ClientKey are PEM files for mutual TLS.
+	ClientCert string `toml:"client_cert,omitempty"`
+	ClientKey  string `toml:"client_key,omitempty"`
+	// TLSServerName overrides the name used for SNI and certificate checks.
+	TLSServerName         string `toml:"tls_server_name,omitempty"`
+	TLSInsecureSkipVerify *bool  `toml:"tls_insecure_skip_verify,omitempty"`
+	// SSHIdentityFile and SSHKnownHostsFile replace the OpenSSH default key
+	// and known_hosts files for ssh:// hosts (comma-separated lists).
+	SSHIdentityFile   string `toml:"ssh_identity_file,omitempty"`
+	SSHKnownHostsFile string `toml:"ssh_known_hosts_file,omitempty"`
+	// Backend selects the server API: "ollama" or "openai" for
+	// OpenAI-compatible servers such as vLLM or the llama.cpp server.
+	Backend string `toml:"backend,omitempty"`
+	// Hosts lists servers with weights (the [[hosts]] table); they are
+	// used after any given in Host.
+	Hosts []HostEntry `toml:"hosts,omitempty"`
+	// Balance is "failover" or "round-robin" for multi-host setups.
+	Balance string `toml:"balance,omitempty"`
+	// Proxy is an http, https or socks5 proxy URL used instead of
+	// HTTP_PROXY/HTTPS_PROXY.
+	Proxy string `toml:"proxy,omitempty"`
+	// NoProxy lists hosts, domains ("*.lab") and CIDR ranges reached
+	// without a proxy, separated by commas.
+	NoProxy string `toml:"no_proxy,omitempty"`
+	// RequestTimeout, FirstTokenTimeout and StreamIdleTimeout bound native
+	// requests and their streamed responses ("30s", "5m"; 0 disables).
+	RequestTimeout    string `toml:"request_timeout,omitempty"`
+	FirstTokenTimeout string `toml:"first_token_timeout,omitempty"`
+	StreamIdleTimeout string `toml:"stream_idle_timeout,omitempty"`
+}
+
+// HostValue is the host setting: one server or a comma-separated list to fail
+// over between. In TOML a list may also be written as an array of strings.
+type HostValue string
+
+// UnmarshalTOML accepts a string or an array of strings.
+func (h *HostValue) UnmarshalTOML(n *unstable.Node) error {
+	switch n.Kind {
+	case unstable.String:
+		*h = HostValue(n.Data)
+		return nil
+	case unstable.Array:
+		var hosts []string
+		it := n.Children()
+		for it.Next() {
+			c := it.Node()
+			if c.Kind != unstable.String {
+				return errors.New("host: expected a string or an array of strings")
+			}
+			hosts = append(hosts, string(c.Data))
+		}
+		*h = HostValue(strings.Join(hosts, ","))
+		return nil
+	default:
+		return errors.New("host: expected a string or an array of strings")
+	}
+}
+
+// HostEntry is one server of a multi-host setup.
+type HostEntry struct {
+	URL string `toml:"url"`
+	// Weight is the server's share of requests with balance = "round-robin".
+	Weight int `toml:"weight,omitempty"`
 }
 
 type LoadOptions struct {
@@ -112,7 +183,8 @@ func readTomlIfExists(path string) (Config, error) {
 		return Config{}, err
 	}
 	var c Config
-	if err := toml.Unmarshal(b, &c); err != nil {
+	dec := toml.NewDecoder(bytes.NewReader(b)).EnableUnmarshalerInterface()
+	if err := dec.Decode(&c); err != nil {
 		return Config{}, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return c, nil
@@ -124,8 +196,10 @@ func fileExists(path string) bool {
 }
 
 func mergeConfig(base, override Config) Config {
-	if strings.TrimSpace(override.Host) != "" {
+	// A host list replaces the inherited one as a whole.
+	if strings.TrimSpace(string(override.Host)) != "" || len(override.Hosts) > 0 {
 		base.Host = override.Host
+		base.Hosts = override.Hosts
 	}
 	if strings.TrimSpace(override.Lang) != "" {
 		base.Lang = override.Lang
@@ -142,5 +216,67 @@ func mergeConfig(base, override Config) Config {
 	if override.Unsafe != nil {
 		base.Unsafe = override.Unsafe
 	}
+	if strings.TrimSpace(override.APIKey) != "" {
+		base.APIKey = override.APIKey
+	}
+	base.Headers = mergeHeaders(base.Headers, override.Headers)
+	if strings.TrimSpace(override.CAFile) != "" {
+		base.CAFile = override.CAFile
+	}
+	if strings.TrimSpace(override.SSHIdentityFile) != "" {
+		base.SSHIdentityFile = override.SSHIdentityFile
+	}
+	if strings.TrimSpace(override.SSHKnownHostsFile) != "" {
+		base.SSHKnownHostsFile = override.SSHKnownHostsFile
+	}
+	if strings.TrimSpace(override.ClientCert) != "" {
+		base.ClientCert = override.ClientCert
+	}
+	if strings.TrimSpace(override.ClientKey) != "" {
+		base.ClientKey = override.ClientKey
+	}
+	if strings.TrimSpace(override.TLSServerName) != "" {
+		base.TLSServerName = override.TLSServerName
+	}
+	if override.TLSInsecureSkipVerify != nil {
+		base.TLSInsecureSkipVerify = override.TLSInsecureSkipVerify
+	}
+	if strings.TrimSpace(override.Backend) != "" {
+		base.Backend = override.Backend
+	}
+	if strings.TrimSpace(override.Balance) != "" {
+		base.Balance = override.Balance
+	}
+	if strings.TrimSpace(override.Proxy) != "" {
+		base.Proxy = override.Proxy
+	}
+	if strings.TrimSpace(override.NoProxy) != "" {
+		base.NoProxy = override.NoProxy
+	}
+	if strings.TrimSpace(override.RequestTimeout) != "" {
+		base.RequestTimeout = override.RequestTimeout
+	}
+	if strings.TrimSpace(override.FirstTokenTimeout) != "" {
+		base.FirstTokenTimeout = override.FirstTokenTimeout
+	}
+	if strings.TrimSpace(override.StreamIdleTimeout) != "" {
+		base.StreamIdleTimeout = override.StreamIdleTimeout
+	}
 	return base
 }
+
+// mergeHeaders returns base with the entries of override added or replaced,
+// without modifying either map.
+func mergeHeaders(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		out[k] = v
+	}
+	return out
+}
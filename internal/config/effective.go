@@ -7,12 +7,38 @@ import (
 )
 
 type Effective struct {
+	// Host is the first of Hosts, the server used when there is only one.
 	Host        string
+	Hosts       []HostEntry
+	Balance     string
 	Lang        string
 	OllamaExe   string
 	Mode        string
 	NoProxyAuto bool
 	Unsafe      bool
+	APIKey      string
+	Headers     map[string]string
+
+	CAFile                string
+	ClientCert            string
+	ClientKey             string
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+
+	// SSH key and known_hosts files; see SSHConfig.
+	SSHIdentityFile   string
+	SSHKnownHostsFile string
+
+	Backend string
+
+	// Proxy overrides HTTP_PROXY/HTTPS_PROXY; NoProxy adds to NO_PROXY.
+	Proxy   string
+	NoProxy []string
+
+	// Timeouts of native requests as configured; see Timeouts.
+	RequestTimeout    string
+	FirstTokenTimeout string
+	StreamIdleTimeout string
 }
 
 type EffectiveMeta struct {
@@ -21,6 +47,7 @@ type EffectiveMeta struct {
 	OllamaExeSource string
 	ModeSource      string
 	UnsafeSource    string
+	APIKeySource    string
 }
 
 type EffectiveOptions struct {
@@ -37,18 +64,29 @@ func ResolveEffective(opts EffectiveOptions) (Effective, EffectiveMeta) {
 	var meta EffectiveMeta
 
 	if strings.TrimSpace(opts.GlobalHostFlag) != "" {
-		out.Host = strings.TrimSpace(opts.GlobalHostFlag)
+		out.Hosts = SplitHosts(opts.GlobalHostFlag)
 		meta.HostSource = "flag"
 	} else if v := strings.TrimSpace(os.Getenv("OLLAMA_HOST")); v != "" {
-		out.Host = v
+		out.Hosts = SplitHosts(v)
 		meta.HostSource = "env"
-	} else if strings.TrimSpace(opts.LoadedConfig.Host) != "" {
-		out.Host = strings.TrimSpace(opts.LoadedConfig.Host)
+	} else if hosts := configHosts(opts.LoadedConfig); len(hosts) > 0 {
+		out.Hosts = hosts
 		meta.HostSource = "config"
 	} else {
-		out.Host = "http://127.0.0.1:11434"
+		out.Hosts = []HostEntry{{URL: "http://127.0.0.1:11434"}}
 		meta.HostSource = "default"
 	}
+	for i, h := range out.Hosts {
+		// Invalid values are kept as given so validation can report them.
+		if n, err := NormalizeHost(h.URL); err == nil {
+			out.Hosts[i].URL = n
+		}
+	}
+	out.Host = out.Hosts[0].URL
+	out.Balance = envOr("OLLAMA_REMOTE_BALANCE", opts.LoadedConfig.Balance)
+	if out.Balance == "" {
+		out.Balance = "failover"
+	}
 
 	if strings.TrimSpace(opts.GlobalLangFlag) != "" {
 		out.Lang = strings.TrimSpace(opts.GlobalLangFlag)
@@ -109,9 +147,93 @@ func ResolveEffective(opts EffectiveOptions) (Effective, EffectiveMeta) {
 		out.Unsafe = false
 		meta.UnsafeSource = "default"
 	}
+
+	// Secrets have no flag so they never show up in process listings.
+	if v := strings.TrimSpace(os.Getenv("OLLAMA_REMOTE_API_KEY")); v != "" {
+		out.APIKey = v
+		meta.APIKeySource = "env"
+	} else if v := strings.TrimSpace(opts.LoadedConfig.APIKey); v != "" {
+		out.APIKey = v
+		meta.APIKeySource = "config"
+	}
+	out.Headers = opts.LoadedConfig.Headers
+	if v := strings.TrimSpace(os.Getenv("OLLAMA_REMOTE_HEADERS")); v != "" {
+		out.Headers = mergeHeaders(out.Headers, ParseHeaderList(v))
+	}
+
+	out.CAFile = envOr("OLLAMA_REMOTE_CA_FILE", opts.LoadedConfig.CAFile)
+	out.SSHIdentityFile = envOr("OLLAMA_REMOTE_SSH_IDENTITY_FILE", opts.LoadedConfig.SSHIdentityFile)
+	out.SSHKnownHostsFile = envOr("OLLAMA_REMOTE_SSH_KNOWN_HOSTS_FILE", opts.LoadedConfig.SSHKnownHostsFile)
+	out.ClientCert = envOr("OLLAMA_REMOTE_CLIENT_CERT", opts.LoadedConfig.ClientCert)
+	out.ClientKey = envOr("OLLAMA_REMOTE_CLIENT_KEY", opts.LoadedConfig.ClientKey)
+	out.TLSServerName = envOr("OLLAMA_REMOTE_TLS_SERVER_NAME", opts.LoadedConfig.TLSServerName)
+	if v := strings.TrimSpace(os.Getenv("OLLAMA_REMOTE_TLS_INSECURE_SKIP_VERIFY")); v != "" {
+		out.TLSInsecureSkipVerify = parseBool(v)
+	} else if opts.LoadedConfig.TLSInsecureSkipVerify != nil {
+		out.TLSInsecureSkipVerify = *opts.LoadedConfig.TLSInsecureSkipVerify
+	}
+	out.Backend = envOr("OLLAMA_REMOTE_BACKEND", opts.LoadedConfig.Backend)
+	if out.Backend == "" {
+		out.Backend = "ollama"
+	}
+	out.Proxy = envOr("OLLAMA_REMOTE_PROXY", opts.LoadedConfig.Proxy)
+	out.NoProxy = SplitNoProxy(envOr("OLLAMA_REMOTE_NO_PROXY", opts.LoadedConfig.NoProxy))
+	out.RequestTimeout = envOr("OLLAMA_REMOTE_REQUEST_TIMEOUT", opts.LoadedConfig.RequestTimeout)
+	out.FirstTokenTimeout = envOr("OLLAMA_REMOTE_FIRST_TOKEN_TIMEOUT", opts.LoadedConfig.FirstTokenTimeout)
+	out.StreamIdleTimeout = envOr("OLLAMA_REMOTE_STREAM_IDLE_TIMEOUT", opts.LoadedConfig.StreamIdleTimeout)
 	return out, meta
 }
 
+// HostList returns the URLs of e.Hosts as one comma-separated value, the
+// form the host setting and OLLAMA_HOST accept.
+func (e Effective) HostList() string {
+	if len(e.Hosts) == 0 {
+		return e.Host
+	}
+	urls := make([]string, len(e.Hosts))
+	for i, h := range e.Hosts {
+		urls[i] = h.URL
+	}
+	return strings.Join(urls, ",")
+}
+
+// SplitHosts parses a comma-separated host list. Empty entries are skipped,
+// but at least one (possibly empty) entry is returned.
+func SplitHosts(v string) []HostEntry {
+	var out []HostEntry
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			out = append(out, HostEntry{URL: h})
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, HostEntry{URL: strings.TrimSpace(v)})
+	}
+	return out
+}
+
+// configHosts returns the hosts of the host setting followed by the
+// [[hosts]] table.
+func configHosts(c Config) []HostEntry {
+	var out []HostEntry
+	if v := strings.TrimSpace(string(c.Host)); v != "" {
+		out = SplitHosts(v)
+	}
+	for _, h := range c.Hosts {
+		out = append(out, HostEntry{URL: strings.TrimSpace(h.URL), Weight: h.Weight})
+	}
+	return out
+}
+
+// envOr returns the trimmed value of the environment variable key, or the
+// trimmed fallback when it is unset.
+func envOr(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return strings.TrimSpace(fallback)
+}
+
 func parseBool(v string) bool {
 	v = strings.TrimSpace(v)
 	return v == "1" || strings.EqualFold(v, "true") || strings.EqualFold(v, "yes") || strings.EqualFold(v, "y")
@@ -150,13 +272,41 @@ func BuildChildEnv(opts ChildEnvOptions) ([]string, ChildEnvMeta, error) {
 
 	if strings.TrimSpace(opts.Effective.Host) != "" {
 		env["OLLAMA_HOST"] = strings.TrimSpace(opts.Effective.Host)
-		if u, err := url.Parse(env["OLLAMA_HOST"]); err == nil {
+		if u, err := ParseHostURL(env["OLLAMA_HOST"]); err == nil {
+			// Pass the cleaned URL so a base path reaches the upstream CLI
+			// in the same form native mode uses.
+			env["OLLAMA_HOST"] = u.String()
 			meta.HostURL = u
-			if opts.Effective.NoProxyAuto {
-				addNoProxy(env, u)
+		}
+	}
+	if opts.Effective.NoProxyAuto {
+		// Every host is exempted since wrapper mode may select any.
+		hosts := opts.Effective.Hosts
+		if len(hosts) == 0 {
+			hosts = []HostEntry{{URL: opts.Effective.Host}}
+		}
+		for _, h := range hosts {
+			// Socket and SSH hosts are never reached through a proxy.
+			if u, err := ParseHostURL(h.URL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				addNoProxy(env, u.Hostname())
 			}
 		}
 	}
+	if p := strings.TrimSpace(opts.Effective.Proxy); p != "" {
+		if u, err := ParseProxyURL(p); err == nil {
+			p = u.String()
+		}
+		// Go programs such as the upstream CLI prefer the upper-case names.
+		env["HTTP_PROXY"] = p
+		env["HTTPS_PROXY"] = p
+	}
+	for _, e := range opts.Effective.NoProxy {
+		// Go's NO_PROXY spells "*.lab" as ".lab".
+		if d, ok := strings.CutPrefix(e, "*."); ok {
+			e = "." + d
+		}
+		addNoProxy(env, e)
+	}
 	if meta.OllamaExeSet {
 		env["OLLAMA_EXE"] = strings.TrimSpace(opts.Effective.OllamaExe)
 	}
@@ -168,12 +318,15 @@ func BuildChildEnv(opts ChildEnvOptions) ([]string, ChildEnvMeta, error) {
 	return out, meta, nil
 }
 
-func addNoProxy(env map[string]string, u *url.URL) {
-	host := u.Hostname()
+func addNoProxy(env map[string]string, host string) {
 	if host == "" {
 		return
 	}
 	cur := env["NO_PROXY"]
+	if cur == "" {
+		// Go only reads no_proxy when NO_PROXY is unset; keep its entries.
+		cur = env["no_proxy"]
+	}
 	if cur == "" {
 		env["NO_PROXY"] = host
 		return
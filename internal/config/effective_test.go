@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func envValue(env []string, key string) string {
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestBuildChildEnvHost(t *testing.T) {
+	env, meta, err := BuildChildEnv(ChildEnvOptions{
+		Existing:  []string{"OLLAMA_HOST=http://old:11434", "NO_PROXY=localhost"},
+		Effective: Effective{Host: "https://gw.example.com/ollama/", NoProxyAuto: true},
+	})
+	if err != nil {
+		t.Fatalf("BuildChildEnv: %v", err)
+	}
+	if got := envValue(env, "OLLAMA_HOST"); got != "https://gw.example.com/ollama" {
+		t.Fatalf("expected cleaned host with base path, got %q", got)
+	}
+	if got := envValue(env, "NO_PROXY"); got != "localhost,gw.example.com" {
+		t.Fatalf("expected host added to NO_PROXY, got %q", got)
+	}
+	if meta.HostURL == nil || meta.HostURL.Path != "/ollama" {
+		t.Fatalf("unexpected host URL: %v", meta.HostURL)
+	}
+}
+
+func TestBuildChildEnvUnixSocket(t *testing.T) {
+	env, meta, err := BuildChildEnv(ChildEnvOptions{
+		Existing:  []string{"NO_PROXY=localhost"},
+		Effective: Effective{Host: "unix:///run/ollama/ollama.sock", NoProxyAuto: true},
+	})
+	if err != nil {
+		t.Fatalf("BuildChildEnv: %v", err)
+	}
+	if got := envValue(env, "NO_PROXY"); got != "localhost" {
+		t.Fatalf("socket hosts must not touch NO_PROXY, got %q", got)
+	}
+	if meta.HostURL == nil || meta.HostURL.Scheme != "unix" {
+		t.Fatalf("unexpected host URL: %v", meta.HostURL)
+	}
+}
+
+func TestBuildChildEnvProxy(t *testing.T) {
+	env, _, err := BuildChildEnv(ChildEnvOptions{
+		Existing: []string{"HTTPS_PROXY=http://old:3128", "NO_PROXY=localhost"},
+		Effective: Effective{
+			Host:    "http://gpu.lab:11434",
+			Proxy:   "socks5://proxy.corp:1080",
+			NoProxy: []string{"*.lab", "10.0.0.0/8"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildChildEnv: %v", err)
+	}
+	for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+		if got := envValue(env, k); got != "socks5://proxy.corp:1080" {
+			t.Fatalf("%s = %q", k, got)
+		}
+	}
+	if got := envValue(env, "NO_PROXY"); got != "localhost,.lab,10.0.0.0/8" {
+		t.Fatalf("expected bypass entries in Go's NO_PROXY form, got %q", got)
+	}
+}
+
+func TestBuildChildEnvLowerNoProxy(t *testing.T) {
+	// Entries from a lower-case no_proxy survive once NO_PROXY is set.
+	env, _, err := BuildChildEnv(ChildEnvOptions{
+		Existing:  []string{"no_proxy=gpu.lab"},
+		Effective: Effective{Host: "http://127.0.0.1:11434", Proxy: "http://proxy.corp:3128", NoProxy: []string{"10.0.0.0/8"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildChildEnv: %v", err)
+	}
+	if got := envValue(env, "NO_PROXY"); got != "gpu.lab,10.0.0.0/8" {
+		t.Fatalf("NO_PROXY = %q", got)
+	}
+}
+
+func TestHostShorthand(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "10.0.0.5")
+	eff, meta := ResolveEffective(EffectiveOptions{})
+	if eff.Host != "http://10.0.0.5:11434" || meta.HostSource != "env" {
+		t.Fatalf("expected normalized env host, got %q from %s", eff.Host, meta.HostSource)
+	}
+
+	env, _, err := BuildChildEnv(ChildEnvOptions{
+		Effective: Effective{Host: ":11434", NoProxyAuto: true},
+	})
+	if err != nil {
+		t.Fatalf("BuildChildEnv: %v", err)
+	}
+	if got := envValue(env, "OLLAMA_HOST"); got != "http://127.0.0.1:11434" {
+		t.Fatalf("expected expanded OLLAMA_HOST, got %q", got)
+	}
+}
+
+func TestHostList(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	data := `host = ["gpu1", "http://gpu2:8080"]
+balance = "round-robin"
+
+[[hosts]]
+url = "gpu3:11434"
+weight = 2
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readTomlIfExists(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	t.Setenv("OLLAMA_HOST", "")
+	eff, meta := ResolveEffective(EffectiveOptions{LoadedConfig: cfg})
+	want := []HostEntry{
+		{URL: "http://gpu1:11434"},
+		{URL: "http://gpu2:8080"},
+		{URL: "http://gpu3:11434", Weight: 2},
+	}
+	if !reflect.DeepEqual(eff.Hosts, want) || eff.Host != want[0].URL || meta.HostSource != "config" {
+		t.Fatalf("hosts = %+v (host %q, source %s)", eff.Hosts, eff.Host, meta.HostSource)
+	}
+	if eff.Balance != "round-robin" {
+		t.Fatalf("balance = %q", eff.Balance)
+	}
+
+	// A project file with its own host replaces the whole list.
+	merged := mergeConfig(cfg, Config{Host: "gpu9"})
+	if merged.Hosts != nil || merged.Host != "gpu9" {
+		t.Fatalf("merged = %+v", merged)
+	}
+
+	t.Setenv("OLLAMA_HOST", "gpu1, gpu2")
+	eff, _ = ResolveEffective(EffectiveOptions{LoadedConfig: cfg})
+	if got := eff.HostList(); got != "http://gpu1:11434,http://gpu2:11434" {
+		t.Fatalf("env hosts = %q", got)
+	}
+	env, _, _ := BuildChildEnv(ChildEnvOptions{Effective: eff})
+	if got := envValue(env, "OLLAMA_HOST"); got != "http://gpu1:11434" {
+		t.Fatalf("child OLLAMA_HOST = %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("host = [1, 2]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readTomlIfExists(path); err == nil {
+		t.Fatal("expected error for non-string hosts")
+	}
+}
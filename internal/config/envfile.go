@@ -44,7 +44,8 @@ func readEnvIfExists(path string) (map[string]string, error) {
 
 func mergeEnvIntoConfig(base Config, env map[string]string) Config {
 	if v := strings.TrimSpace(env["OLLAMA_HOST"]); v != "" {
-		base.Host = v
+		base.Host = HostValue(v)
+		base.Hosts = nil
 	}
 	if v := strings.TrimSpace(env["OLLAMA_EXE"]); v != "" {
 		base.OllamaExe = v
@@ -63,5 +64,54 @@ func mergeEnvIntoConfig(base Config, env map[string]string) Config {
 		b := (v == "1" || strings.EqualFold(v, "true") || strings.EqualFold(v, "yes"))
 		base.Unsafe = &b
 	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_API_KEY"]); v != "" {
+		base.APIKey = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_HEADERS"]); v != "" {
+		base.Headers = mergeHeaders(base.Headers, ParseHeaderList(v))
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_CA_FILE"]); v != "" {
+		base.CAFile = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_SSH_IDENTITY_FILE"]); v != "" {
+		base.SSHIdentityFile = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_SSH_KNOWN_HOSTS_FILE"]); v != "" {
+		base.SSHKnownHostsFile = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_CLIENT_CERT"]); v != "" {
+		base.ClientCert = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_CLIENT_KEY"]); v != "" {
+		base.ClientKey = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_TLS_SERVER_NAME"]); v != "" {
+		base.TLSServerName = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_TLS_INSECURE_SKIP_VERIFY"]); v != "" {
+		b := parseBool(v)
+		base.TLSInsecureSkipVerify = &b
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_BACKEND"]); v != "" {
+		base.Backend = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_BALANCE"]); v != "" {
+		base.Balance = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_PROXY"]); v != "" {
+		base.Proxy = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_NO_PROXY"]); v != "" {
+		base.NoProxy = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_REQUEST_TIMEOUT"]); v != "" {
+		base.RequestTimeout = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_FIRST_TOKEN_TIMEOUT"]); v != "" {
+		base.FirstTokenTimeout = v
+	}
+	if v := strings.TrimSpace(env["OLLAMA_REMOTE_STREAM_IDLE_TIMEOUT"]); v != "" {
+		base.StreamIdleTimeout = v
+	}
 	return base
 }
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces secrets in user-facing output.
+const redactedValue = "<redacted>"
+
+// minRedactLen keeps short, non-secret header values (such as "1") from
+// being scrubbed out of unrelated text.
+const minRedactLen = 8
+
+// ParseHeaderList parses "Name: value" pairs separated by ";" or newlines, as
+// used by OLLAMA_REMOTE_HEADERS. Entries without a colon keep an empty value
+// so ValidateHeaders can report them.
+func ParseHeaderList(s string) map[string]string {
+	out := map[string]string{}
+	for _, entry := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(entry, ":")
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return out
+}
+
+// ValidateHeaders rejects header names that are not HTTP tokens and values
+// that are empty or could inject additional header lines.
+func ValidateHeaders(h map[string]string) error {
+	for _, k := range sortedKeys(h) {
+		if !isToken(k) {
+			return fmt.Errorf("invalid header name %q", k)
+		}
+		v := h[k]
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("header %s: empty value (expected \"Name: value\")", k)
+		}
+		if strings.ContainsAny(v, "\r\n\x00") {
+			return fmt.Errorf("header %s: value contains control characters", k)
+		}
+	}
+	return nil
+}
+
+// RequestHeaders returns the headers sent with every API request: the
+// configured [headers] plus "Authorization: Bearer <api_key>" when api_key is
+// set, which takes precedence over an Authorization entry in [headers].
+func (e Effective) RequestHeaders() http.Header {
+	h := http.Header{}
+	for _, k := range sortedKeys(e.Headers) {
+		h.Set(k, strings.TrimSpace(e.Headers[k]))
+	}
+	if e.APIKey != "" {
+		h.Set("Authorization", "Bearer "+e.APIKey)
+	}
+	return h
+}
+
+// HeaderNames returns the canonical names of RequestHeaders, for display
+// without values.
+func (e Effective) HeaderNames() []string {
+	h := e.RequestHeaders()
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Redact replaces the API key and header values in s, so error messages that
+// echo a request cannot leak credentials.
+func (e Effective) Redact(s string) string {
+	var secrets []string
+	if e.APIKey != "" {
+		secrets = append(secrets, e.APIKey)
+	}
+	for k, vs := range e.RequestHeaders() {
+		for _, v := range vs {
+			if len(v) >= minRedactLen || strings.EqualFold(k, "Authorization") {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+	// Replace longer secrets first so "Bearer <key>" is not left half-redacted.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for _, v := range secrets {
+		if v != "" {
+			s = strings.ReplaceAll(s, v, redactedValue)
+		}
+	}
+	return s
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isToken reports whether s is a valid HTTP header field name (RFC 9110 token).
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHeaderList(t *testing.T) {
+	got := ParseHeaderList("X-Tenant: team-a; X-Trace: on\nBroken")
+	if got["X-Tenant"] != "team-a" || got["X-Trace"] != "on" {
+		t.Fatalf("unexpected headers: %v", got)
+	}
+	if v, ok := got["Broken"]; !ok || v != "" {
+		t.Fatalf("expected entry without value to be kept, got %v", got)
+	}
+	if err := ValidateHeaders(got); err == nil || !strings.Contains(err.Error(), "Broken") {
+		t.Fatalf("expected empty value error, got %v", err)
+	}
+	if err := ValidateHeaders(map[string]string{"Bad Name": "x"}); err == nil {
+		t.Fatal("expected invalid name error")
+	}
+	if err := ValidateHeaders(map[string]string{"X-A": "v\r\nX-B: injected"}); err == nil {
+		t.Fatal("expected control character error")
+	}
+}
+
+func TestRequestHeadersAndRedact(t *testing.T) {
+	t.Setenv("OLLAMA_REMOTE_API_KEY", "")
+	t.Setenv("OLLAMA_REMOTE_HEADERS", "X-Tenant: team-a")
+	eff, meta := ResolveEffective(EffectiveOptions{LoadedConfig: Config{
+		APIKey:  "sk-config-secret",
+		Headers: map[string]string{"authorization": "Basic overridden", "x-gateway-token": "gw-0123456789"},
+	}})
+	if meta.APIKeySource != "config" {
+		t.Fatalf("expected api key from config, got %q", meta.APIKeySource)
+	}
+	h := eff.RequestHeaders()
+	if got := h.Get("Authorization"); got != "Bearer sk-config-secret" {
+		t.Fatalf("api_key must win over [headers], got %q", got)
+	}
+	if h.Get("X-Gateway-Token") != "gw-0123456789" || h.Get("X-Tenant") != "team-a" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+	if got := strings.Join(eff.HeaderNames(), ","); got != "Authorization,X-Gateway-Token,X-Tenant" {
+		t.Fatalf("unexpected names: %s", got)
+	}
+
+	msg := eff.Redact(`401: bad token "Bearer sk-config-secret" via gw-0123456789 for team-a`)
+	if strings.Contains(msg, "sk-config") || strings.Contains(msg, "gw-0123") {
+		t.Fatalf("secret leaked: %s", msg)
+	}
+	// Short values are not treated as secrets.
+	if !strings.Contains(msg, "team-a") {
+		t.Fatalf("short value redacted: %s", msg)
+	}
+
+	t.Setenv("OLLAMA_REMOTE_API_KEY", "sk-env")
+	if eff, meta := ResolveEffective(EffectiveOptions{LoadedConfig: Config{APIKey: "sk-config"}}); eff.APIKey != "sk-env" || meta.APIKeySource != "env" {
+		t.Fatalf("env must override config, got %q from %s", eff.APIKey, meta.APIKeySource)
+	}
+}
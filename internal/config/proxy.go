@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ProxyURL checks proxy and no_proxy and returns the configured proxy, or
+// nil when none is set and the environment's proxy applies.
+func (e Effective) ProxyURL() (*url.URL, error) {
+	if entry, err := CheckNoProxy(e.NoProxy); err != nil {
+		return nil, fmt.Errorf("no_proxy entry %q: %w", entry, err)
+	}
+	if strings.TrimSpace(e.Proxy) == "" {
+		return nil, nil
+	}
+	return ParseProxyURL(e.Proxy)
+}
+
+// ParseProxyURL validates a proxy setting: an http, https, socks5 or socks5h
+// URL with a host. A bare "host:port" means an HTTP proxy.
+func ParseProxyURL(in string) (*url.URL, error) {
+	in = strings.TrimSpace(in)
+	if !strings.Contains(in, "://") {
+		in = "http://" + in
+	}
+	u, err := url.Parse(in)
+	if err != nil {
+		// The parse error repeats the URL, which may hold a password.
+		return nil, errors.New("invalid proxy: malformed URL")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy scheme: %s (use http, https or socks5)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy: missing host")
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid proxy: only scheme, credentials, host and port are allowed")
+	}
+	u.Path = ""
+	return u, nil
+}
+
+// SplitNoProxy parses a proxy bypass list separated by commas or spaces, as
+// in NO_PROXY.
+func SplitNoProxy(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// CheckNoProxy validates the entries of a bypass list and returns the first
+// invalid one with its error. Entries are host names, ".domain" or
+// "*.domain" for subdomains, IP addresses, CIDR ranges, any of these with
+// ":port", or "*" for every host.
+func CheckNoProxy(entries []string) (string, error) {
+	for _, e := range entries {
+		if strings.Contains(e, "/") {
+			if _, _, err := net.ParseCIDR(e); err != nil {
+				return e, fmt.Errorf("invalid CIDR range")
+			}
+			continue
+		}
+		name := e
+		if h, port, err := net.SplitHostPort(e); err == nil {
+			if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+				return e, fmt.Errorf("bad port %q", port)
+			}
+			name = h
+		}
+		if strings.Contains(strings.TrimPrefix(name, "*."), "*") && name != "*" {
+			return e, fmt.Errorf("a wildcard is only allowed as \"*\" or a leading \"*.\"")
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SSHConfig holds the ssh_identity_file and ssh_known_hosts_file settings;
+// an empty list keeps the OpenSSH default files.
+type SSHConfig struct {
+	IdentityFiles   []string
+	KnownHostsFiles []string
+}
+
+// SSHConfig splits the comma-separated SSH file settings, expands a leading
+// "~/" and checks that every file can be read.
+func (e Effective) SSHConfig() (SSHConfig, error) {
+	var c SSHConfig
+	for _, s := range []struct {
+		key string
+		val string
+		out *[]string
+	}{
+		{"ssh_identity_file", e.SSHIdentityFile, &c.IdentityFiles},
+		{"ssh_known_hosts_file", e.SSHKnownHostsFile, &c.KnownHostsFiles},
+	} {
+		for _, p := range strings.Split(s.val, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			p, err := expandHome(p)
+			if err != nil {
+				return SSHConfig{}, fmt.Errorf("%s: %w", s.key, err)
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return SSHConfig{}, fmt.Errorf("%s: %w", s.key, err)
+			}
+			f.Close()
+			*s.out = append(*s.out, p)
+		}
+	}
+	return c, nil
+}
+
+func expandHome(p string) (string, error) {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~")), nil
+}
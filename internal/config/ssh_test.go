@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEffectiveSSHConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	key := filepath.Join(home, "id_test")
+	known := filepath.Join(home, "known_hosts")
+	for _, p := range []string{key, known} {
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Effective{SSHIdentityFile: "~/id_test, " + known, SSHKnownHostsFile: known}.SSHConfig()
+	want := SSHConfig{IdentityFiles: []string{key, known}, KnownHostsFiles: []string{known}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("SSHConfig = %+v, %v; want %+v", got, err, want)
+	}
+	if got, err := (Effective{}).SSHConfig(); err != nil || !reflect.DeepEqual(got, SSHConfig{}) {
+		t.Fatalf("empty SSHConfig = %+v, %v", got, err)
+	}
+	_, err = Effective{SSHKnownHostsFile: filepath.Join(home, "missing")}.SSHConfig()
+	if err == nil || !strings.HasPrefix(err.Error(), "ssh_known_hosts_file: ") {
+		t.Fatalf("expected a ssh_known_hosts_file error, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeouts are the request_timeout, first_token_timeout and
+// stream_idle_timeout settings; zero disables one.
+type Timeouts struct {
+	Request    time.Duration
+	FirstToken time.Duration
+	StreamIdle time.Duration
+}
+
+// Timeouts parses the timeout settings (see ParseTimeout).
+func (e Effective) Timeouts() (Timeouts, error) {
+	var t Timeouts
+	for _, s := range []struct {
+		key string
+		val string
+		out *time.Duration
+	}{
+		{"request_timeout", e.RequestTimeout, &t.Request},
+		{"first_token_timeout", e.FirstTokenTimeout, &t.FirstToken},
+		{"stream_idle_timeout", e.StreamIdleTimeout, &t.StreamIdle},
+	} {
+		d, err := ParseTimeout(s.val)
+		if err != nil {
+			return Timeouts{}, fmt.Errorf("%s: %w", s.key, err)
+		}
+		*s.out = d
+	}
+	return t, nil
+}
+
+// ParseTimeout parses a timeout given as a Go duration ("90s", "5m") or a
+// number of seconds. Empty and "0" mean no timeout.
+func ParseTimeout(v string) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		n, nerr := strconv.ParseFloat(v, 64)
+		if nerr != nil {
+			return 0, fmt.Errorf("invalid duration %q (e.g. 30s, 5m, 0 to disable)", v)
+		}
+		d = time.Duration(n * float64(time.Second))
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", v)
+	}
+	return d, nil
+}
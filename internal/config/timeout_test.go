@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":     0,
+		"0":    0,
+		"30s":  30 * time.Second,
+		"5m":   5 * time.Minute,
+		" 90 ": 90 * time.Second,
+		"1.5":  1500 * time.Millisecond,
+	} {
+		if got, err := ParseTimeout(in); err != nil || got != want {
+			t.Errorf("ParseTimeout(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"soon", "-5s", "-1", "10 minutes"} {
+		if _, err := ParseTimeout(in); err == nil {
+			t.Errorf("ParseTimeout(%q): expected an error", in)
+		}
+	}
+}
+
+func TestEffectiveTimeouts(t *testing.T) {
+	got, err := Effective{RequestTimeout: "10m", StreamIdleTimeout: "30"}.Timeouts()
+	if err != nil || got != (Timeouts{Request: 10 * time.Minute, StreamIdle: 30 * time.Second}) {
+		t.Fatalf("Timeouts = %+v, %v", got, err)
+	}
+	if _, err := (Effective{FirstTokenTimeout: "later"}).Timeouts(); err == nil {
+		t.Fatal("expected an error for first_token_timeout")
+	}
+}
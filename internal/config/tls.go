@@ -0,0 +1,50 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSConfig builds the client TLS settings from ca_file, client_cert and
+// client_key, tls_server_name and tls_insecure_skip_verify. It returns nil
+// when none of them are set, so the transport keeps Go's defaults.
+func (e Effective) TLSConfig() (*tls.Config, error) {
+	if e.CAFile == "" && e.ClientCert == "" && e.ClientKey == "" && e.TLSServerName == "" && !e.TLSInsecureSkipVerify {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         e.TLSServerName,
+		InsecureSkipVerify: e.TLSInsecureSkipVerify,
+	}
+	if e.CAFile != "" {
+		pem, err := os.ReadFile(e.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ca_file: %w", err)
+		}
+		// The CA extends the system roots; an internal CA usually sits
+		// alongside public endpoints such as model registries.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file %s: no PEM certificates found", e.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	switch {
+	case e.ClientCert != "" && e.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(e.ClientCert, e.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client_cert/client_key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case e.ClientCert != "" || e.ClientKey != "":
+		return nil, errors.New("client_cert and client_key must be set together")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate and its key as PEM files.
+func writeTestCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Test CA"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	if cfg, err := (Effective{}).TLSConfig(); cfg != nil || err != nil {
+		t.Fatalf("expected no TLS config by default, got %v %v", cfg, err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+	cfg, err := Effective{CAFile: certFile, ClientCert: certFile, ClientKey: keyFile, TLSServerName: "ollama.internal"}.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig: %v", err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 || cfg.ServerName != "ollama.internal" || cfg.InsecureSkipVerify {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	if _, err := (Effective{ClientCert: certFile}).TLSConfig(); err == nil {
+		t.Fatal("expected error for client_cert without client_key")
+	}
+	if _, err := (Effective{CAFile: keyFile}).TLSConfig(); err == nil {
+		t.Fatal("expected error for ca_file without certificates")
+	}
+	if _, err := (Effective{CAFile: filepath.Join(dir, "missing.pem")}).TLSConfig(); err == nil {
+		t.Fatal("expected error for missing ca_file")
+	}
+}
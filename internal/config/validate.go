@@ -2,10 +2,62 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
 )
 
+// NormalizeBackend validates and normalizes the server API backend.
+//
+// Allowed values: ollama (the default), openai.
+func NormalizeBackend(in string) (string, error) {
+	v := strings.TrimSpace(strings.ToLower(in))
+	if v == "" {
+		v = "ollama"
+	}
+	switch v {
+	case "ollama", "openai":
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid backend: %s", in)
+	}
+}
+
+// NormalizeBalance validates and normalizes how requests are spread over
+// several hosts.
+//
+// Allowed values: failover (the default), round-robin.
+func NormalizeBalance(in string) (string, error) {
+	v := strings.TrimSpace(strings.ToLower(in))
+	switch v {
+	case "", "failover":
+		return "failover", nil
+	case "round-robin", "roundrobin", "round_robin":
+		return "round-robin", nil
+	default:
+		return "", fmt.Errorf("invalid balance: %s", in)
+	}
+}
+
+// CheckHosts validates every host of a (multi-host) setup and returns the
+// first invalid entry with its error.
+func CheckHosts(hosts []HostEntry) (string, error) {
+	for _, h := range hosts {
+		if _, err := ParseHostURL(h.URL); err != nil {
+			return h.URL, err
+		}
+		if h.Weight < 0 {
+			return h.URL, fmt.Errorf("invalid weight %d", h.Weight)
+		}
+	}
+	return "", nil
+}
+
+// DefaultPort is the port upstream ollama assumes when OLLAMA_HOST has none.
+const DefaultPort = "11434"
+
 // NormalizeMode validates and normalizes the requested execution mode.
 //
 // Allowed values: auto, wrapper, native.
@@ -22,9 +74,79 @@ func NormalizeMode(in string) (string, error) {
 	}
 }
 
+// NormalizeHost expands the OLLAMA_HOST shorthands accepted by upstream
+// ollama ("10.0.0.5", "10.0.0.5:11434", ":11434", "[::1]", "0.0.0.0") into a
+// full URL: scheme http, port 11434 and host 127.0.0.1 when omitted.
+// Unspecified addresses such as 0.0.0.0 are kept as written, so the upstream
+// CLI gets the host the user gave; ParseHostURL dials them on the loopback
+// address. Values with a scheme are returned unchanged.
+func NormalizeHost(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if host == "" || !isHostShorthand(host) {
+		return host, nil
+	}
+	hostport, p, _ := strings.Cut(host, "/")
+	name, port := hostport, ""
+	if h, pt, err := net.SplitHostPort(hostport); err == nil {
+		name, port = h, pt
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "["), "]")
+	if port == "" {
+		port = DefaultPort
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid host: bad port %q", port)
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		name = ip.String()
+	} else if name == "" {
+		name = "127.0.0.1"
+	} else if strings.Contains(name, ":") {
+		return "", fmt.Errorf("invalid host: %s (bracket IPv6 addresses, e.g. [::1]:11434)", hostport)
+	}
+	u := url.URL{Scheme: "http", Host: net.JoinHostPort(name, port)}
+	if p != "" {
+		u.Path = "/" + p
+	}
+	return u.String(), nil
+}
+
+// isHostShorthand reports whether host lacks a scheme. "localhost:11434"
+// parses as a URL with scheme "localhost", so only the schemes ParseHostURL
+// understands count.
+func isHostShorthand(host string) bool {
+	if strings.Contains(host, "://") {
+		return false
+	}
+	scheme, _, _ := strings.Cut(host, ":")
+	switch strings.ToLower(scheme) {
+	case "http", "https", "unix", "ssh":
+		return false
+	}
+	return true
+}
+
 // ParseHostURL validates host as an absolute http(s) URL suitable as an API base.
+// Upstream shorthands are expanded first (see NormalizeHost).
+//
+// A path is kept as a base path prefix for servers exposed under a reverse
+// proxy location ("https://gw.example.com/ollama"); it is cleaned and returned
+// without a trailing slash.
+//
+// An unspecified address ("http://0.0.0.0:11434", "http://[::]:11434") only
+// makes sense for a listener; as a server to connect to it means this machine
+// and is replaced by the loopback address.
+//
+// "unix:///path/to/ollama.sock" selects a Unix domain socket; the path is the
+// socket itself, so it cannot carry a base path.
+//
+// "ssh://user@gpu-box/127.0.0.1:11434" tunnels through an SSH server; the path
+// is the address the server dials, 127.0.0.1:11434 when omitted.
 func ParseHostURL(host string) (*url.URL, error) {
-	host = strings.TrimSpace(host)
+	host, err := NormalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
 	if host == "" {
 		return nil, fmt.Errorf("empty host")
 	}
@@ -32,14 +154,32 @@ func ParseHostURL(host string) (*url.URL, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid host: %w", err)
 	}
-	if u.Scheme != "http" && u.Scheme != "https" {
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid host: missing host")
+		}
+		if ip := net.ParseIP(u.Hostname()); ip != nil && ip.IsUnspecified() {
+			loopback := "127.0.0.1"
+			if ip.To4() == nil {
+				loopback = "[::1]"
+			}
+			if port := u.Port(); port != "" {
+				loopback = net.JoinHostPort(strings.Trim(loopback, "[]"), port)
+			}
+			u.Host = loopback
+		}
+	case "unix":
+		if u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+			return nil, fmt.Errorf("invalid host: unix socket needs an absolute path (unix:///path/to/ollama.sock)")
+		}
+	case "ssh":
+		return parseSSHURL(u)
+	default:
 		return nil, fmt.Errorf("invalid host scheme: %s", u.Scheme)
 	}
-	if u.Host == "" {
-		return nil, fmt.Errorf("invalid host: missing host")
-	}
 	if u.User != nil {
-		return nil, fmt.Errorf("invalid host: userinfo not allowed")
+		return nil, fmt.Errorf("invalid host: userinfo not allowed (use api_key or [headers])")
 	}
 	if u.Fragment != "" {
 		return nil, fmt.Errorf("invalid host: fragment not allowed")
@@ -47,10 +187,40 @@ func ParseHostURL(host string) (*url.URL, error) {
 	if u.RawQuery != "" {
 		return nil, fmt.Errorf("invalid host: query not allowed")
 	}
-	if u.Path != "" && u.Path != "/" {
-		return nil, fmt.Errorf("invalid host: path not allowed")
+	if u.Path != "" {
+		p := path.Clean("/" + u.Path)
+		if p == "/" && u.Scheme != "unix" {
+			p = ""
+		}
+		u.Path = p
+	}
+	u.RawPath = ""
+	return u, nil
+}
+
+// parseSSHURL validates an ssh:// host and fills in the default tunnel target.
+func parseSSHURL(u *url.URL) (*url.URL, error) {
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid host: missing ssh server")
+	}
+	if _, ok := u.User.Password(); ok {
+		return nil, fmt.Errorf("invalid host: ssh passwords are not supported (use a key or ssh-agent)")
+	}
+	if u.Fragment != "" || u.RawQuery != "" {
+		return nil, fmt.Errorf("invalid host: ssh host cannot have a query or fragment")
+	}
+	target := strings.Trim(u.Path, "/")
+	if target == "" {
+		target = "127.0.0.1:" + DefaultPort
+	}
+	name, port, err := net.SplitHostPort(target)
+	if err != nil {
+		name, port = strings.TrimSuffix(strings.TrimPrefix(target, "["), "]"), DefaultPort
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid host: ssh target must be host:port (ssh://user@gpu-box/127.0.0.1:11434)")
 	}
-	u.Path = ""
+	u.Path = "/" + net.JoinHostPort(name, port)
 	u.RawPath = ""
 	return u, nil
 }
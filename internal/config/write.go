@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,7 +53,9 @@ func SetUserConfig(path, key, val string) error {
 
 	switch key {
 	case "host":
-		c.Host = val
+		// Setting host replaces any [[hosts]] table.
+		c.Host = HostValue(val)
+		c.Hosts = nil
 	case "lang":
 		c.Lang = val
 	case "ollama_exe":
@@ -67,13 +70,68 @@ func SetUserConfig(path, key, val string) error {
 		v := strings.TrimSpace(val)
 		b := (v == "1" || strings.EqualFold(v, "true") || strings.EqualFold(v, "yes"))
 		c.Unsafe = &b
+	case "api_key":
+		c.APIKey = strings.TrimSpace(val)
+	case "ca_file":
+		c.CAFile = strings.TrimSpace(val)
+	case "ssh_identity_file":
+		c.SSHIdentityFile = strings.TrimSpace(val)
+	case "ssh_known_hosts_file":
+		c.SSHKnownHostsFile = strings.TrimSpace(val)
+	case "client_cert":
+		c.ClientCert = strings.TrimSpace(val)
+	case "client_key":
+		c.ClientKey = strings.TrimSpace(val)
+	case "tls_server_name":
+		c.TLSServerName = strings.TrimSpace(val)
+	case "tls_insecure_skip_verify":
+		b := parseBool(val)
+		c.TLSInsecureSkipVerify = &b
+	case "backend":
+		c.Backend = strings.TrimSpace(val)
+	case "balance":
+		c.Balance = strings.TrimSpace(val)
+	case "proxy":
+		c.Proxy = strings.TrimSpace(val)
+	case "no_proxy":
+		c.NoProxy = strings.TrimSpace(val)
+	case "request_timeout":
+		c.RequestTimeout = strings.TrimSpace(val)
+	case "first_token_timeout":
+		c.FirstTokenTimeout = strings.TrimSpace(val)
+	case "stream_idle_timeout":
+		c.StreamIdleTimeout = strings.TrimSpace(val)
 	default:
-		return &UnknownKeyError{Key: key}
+		name, ok := strings.CutPrefix(key, "headers.")
+		if !ok || !isToken(name) {
+			return &UnknownKeyError{Key: key}
+		}
+		// Keys are lowercased above; store the canonical form users expect and
+		// drop spellings that differ only in case. An empty value removes it.
+		name = http.CanonicalHeaderKey(name)
+		for k := range c.Headers {
+			if strings.EqualFold(k, name) {
+				delete(c.Headers, k)
+			}
+		}
+		if v := strings.TrimSpace(val); v != "" {
+			if c.Headers == nil {
+				c.Headers = map[string]string{}
+			}
+			c.Headers[name] = v
+		}
 	}
 
 	b, err := toml.Marshal(c)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, b, 0o644)
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return err
+	}
+	if c.APIKey != "" || len(c.Headers) > 0 || strings.Contains(c.Proxy, "@") {
+		// The file now holds credentials; keep it private to the user.
+		return os.Chmod(path, 0o600)
+	}
+	return nil
 }
@@ -0,0 +1,487 @@
+// Package jsonschema validates JSON documents against the subset of JSON Schema
+// that Ollama's structured outputs accept.
+//
+// Supported keywords: type, enum, const, properties, required,
+// additionalProperties, items, prefixItems, minItems, maxItems, uniqueItems,
+// minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and local $ref
+// pointers ("#", "#/$defs/...", "#/definitions/..."). Other keywords are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth bounds $ref resolution so recursive schemas cannot loop forever.
+const maxRefDepth = 64
+
+// Schema is a compiled JSON Schema document.
+type Schema struct {
+	root    any
+	raw     json.RawMessage
+	regexps map[string]*regexp.Regexp
+}
+
+// ValidationError reports the first instance location that does not match the schema.
+type ValidationError struct {
+	// Path is a JSON Pointer to the failing value ("" is the document root).
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	p := e.Path
+	if p == "" {
+		p = "/"
+	}
+	return p + ": " + e.Message
+}
+
+// Compile parses a schema document. The schema must be a JSON object or boolean.
+func Compile(data []byte) (*Schema, error) {
+	data = bytes.TrimSpace(data)
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	switch root.(type) {
+	case map[string]any, bool:
+	default:
+		return nil, errors.New("parse schema: schema must be a JSON object or boolean")
+	}
+	s := &Schema{root: root, raw: json.RawMessage(data), regexps: map[string]*regexp.Regexp{}}
+	if err := s.check(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Raw returns the schema as it was given to Compile.
+func (s *Schema) Raw() json.RawMessage {
+	return s.raw
+}
+
+// Validate decodes doc as JSON and validates it against the schema.
+func (s *Schema) Validate(doc []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return &ValidationError{Message: "invalid JSON: " + err.Error()}
+	}
+	if len(bytes.TrimSpace(doc[dec.InputOffset():])) > 0 {
+		return &ValidationError{Message: "invalid JSON: unexpected data after top-level value"}
+	}
+	return s.validate(s.root, normalize(v), "", 0)
+}
+
+// check precompiles patterns and rejects malformed keyword values up front so
+// that schema mistakes are not reported as validation failures of the output.
+func (s *Schema) check(node any) error {
+	switch n := node.(type) {
+	case map[string]any:
+		if p, ok := n["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("parse schema: invalid pattern %q: %w", p, err)
+			}
+			s.regexps[p] = re
+		}
+		if ref, ok := n["$ref"].(string); ok {
+			if _, err := s.resolve(ref); err != nil {
+				return err
+			}
+		}
+		for k, v := range n {
+			switch k {
+			case "enum", "const", "default", "examples":
+				// Literal values, not subschemas.
+				continue
+			}
+			if err := s.check(v); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, v := range n {
+			if err := s.check(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve looks up a local JSON Pointer reference.
+func (s *Schema) resolve(ref string) (any, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("parse schema: unsupported $ref %q (only local references are supported)", ref)
+	}
+	cur := s.root
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch x := cur.(type) {
+		case map[string]any:
+			next, ok := x[tok]
+			if !ok {
+				return nil, fmt.Errorf("parse schema: unresolved $ref %q", ref)
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, fmt.Errorf("parse schema: unresolved $ref %q", ref)
+			}
+			cur = x[i]
+		default:
+			return nil, fmt.Errorf("parse schema: unresolved $ref %q", ref)
+		}
+	}
+	return cur, nil
+}
+
+func (s *Schema) validate(node, v any, path string, depth int) error {
+	switch n := node.(type) {
+	case bool:
+		if !n {
+			return fail(path, "no value is allowed here")
+		}
+		return nil
+	case map[string]any:
+		return s.validateObject(n, v, path, depth)
+	default:
+		return nil
+	}
+}
+
+func (s *Schema) validateObject(n map[string]any, v any, path string, depth int) error {
+	if ref, ok := n["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			return fail(path, "schema $ref nesting is too deep")
+		}
+		target, err := s.resolve(ref)
+		if err != nil {
+			return err
+		}
+		if err := s.validate(target, v, path, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if t, ok := n["type"]; ok {
+		if err := checkType(t, v, path); err != nil {
+			return err
+		}
+	}
+	if c, ok := n["const"]; ok && !equal(normalize(c), v) {
+		return fail(path, "value must be "+compact(c))
+	}
+	if e, ok := n["enum"].([]any); ok {
+		found := false
+		for _, want := range e {
+			if equal(normalize(want), v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fail(path, "value must be one of "+compact(e))
+		}
+	}
+
+	switch x := v.(type) {
+	case map[string]any:
+		if err := s.validateProperties(n, x, path, depth); err != nil {
+			return err
+		}
+	case []any:
+		if err := s.validateItems(n, x, path, depth); err != nil {
+			return err
+		}
+	case string:
+		if err := s.validateString(n, x, path); err != nil {
+			return err
+		}
+	case float64:
+		if err := validateNumber(n, x, path); err != nil {
+			return err
+		}
+	}
+
+	if all, ok := n["allOf"].([]any); ok {
+		for _, sub := range all {
+			if err := s.validate(sub, v, path, depth); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := n["anyOf"].([]any); ok {
+		var first error
+		matched := false
+		for _, sub := range anyOf {
+			err := s.validate(sub, v, path, depth)
+			if err == nil {
+				matched = true
+				break
+			}
+			if first == nil {
+				first = err
+			}
+		}
+		if !matched {
+			return fail(path, "value does not match any schema in anyOf ("+errMessage(first)+")")
+		}
+	}
+	if oneOf, ok := n["oneOf"].([]any); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if s.validate(sub, v, path, depth) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fail(path, fmt.Sprintf("value must match exactly one schema in oneOf (matched %d)", matches))
+		}
+	}
+	if not, ok := n["not"]; ok {
+		if s.validate(not, v, path, depth) == nil {
+			return fail(path, "value must not match the schema in not")
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateProperties(n map[string]any, obj map[string]any, path string, depth int) error {
+	if req, ok := n["required"].([]any); ok {
+		for _, r := range req {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				return fail(path, fmt.Sprintf("missing required property %q", name))
+			}
+		}
+	}
+	props, _ := n["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if sub, ok := props[k]; ok {
+			if err := s.validate(sub, obj[k], path+"/"+escape(k), depth); err != nil {
+				return err
+			}
+			continue
+		}
+		switch ap := n["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				return fail(path, fmt.Sprintf("unexpected property %q", k))
+			}
+		case map[string]any:
+			if err := s.validate(ap, obj[k], path+"/"+escape(k), depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateItems(n map[string]any, arr []any, path string, depth int) error {
+	if min, ok := intKeyword(n, "minItems"); ok && len(arr) < min {
+		return fail(path, fmt.Sprintf("array must have at least %d items", min))
+	}
+	if max, ok := intKeyword(n, "maxItems"); ok && len(arr) > max {
+		return fail(path, fmt.Sprintf("array must have at most %d items", max))
+	}
+	if u, _ := n["uniqueItems"].(bool); u {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					return fail(path, fmt.Sprintf("items %d and %d are equal", i, j))
+				}
+			}
+		}
+	}
+	start := 0
+	if prefix, ok := n["prefixItems"].([]any); ok {
+		for i := 0; i < len(prefix) && i < len(arr); i++ {
+			if err := s.validate(prefix[i], arr[i], path+"/"+strconv.Itoa(i), depth); err != nil {
+				return err
+			}
+		}
+		start = len(prefix)
+	}
+	if items, ok := n["items"]; ok {
+		for i := start; i < len(arr); i++ {
+			if err := s.validate(items, arr[i], path+"/"+strconv.Itoa(i), depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(n map[string]any, str, path string) error {
+	length := utf8.RuneCountInString(str)
+	if min, ok := intKeyword(n, "minLength"); ok && length < min {
+		return fail(path, fmt.Sprintf("string must be at least %d characters", min))
+	}
+	if max, ok := intKeyword(n, "maxLength"); ok && length > max {
+		return fail(path, fmt.Sprintf("string must be at most %d characters", max))
+	}
+	if p, ok := n["pattern"].(string); ok {
+		if re := s.regexps[p]; re != nil && !re.MatchString(str) {
+			return fail(path, fmt.Sprintf("string does not match pattern %q", p))
+		}
+	}
+	return nil
+}
+
+func validateNumber(n map[string]any, f float64, path string) error {
+	if min, ok := numKeyword(n, "minimum"); ok && f < min {
+		return fail(path, fmt.Sprintf("value must be >= %v", min))
+	}
+	if max, ok := numKeyword(n, "maximum"); ok && f > max {
+		return fail(path, fmt.Sprintf("value must be <= %v", max))
+	}
+	if min, ok := numKeyword(n, "exclusiveMinimum"); ok && f <= min {
+		return fail(path, fmt.Sprintf("value must be > %v", min))
+	}
+	if max, ok := numKeyword(n, "exclusiveMaximum"); ok && f >= max {
+		return fail(path, fmt.Sprintf("value must be < %v", max))
+	}
+	if m, ok := numKeyword(n, "multipleOf"); ok && m > 0 {
+		if q := f / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			return fail(path, fmt.Sprintf("value must be a multiple of %v", m))
+		}
+	}
+	return nil
+}
+
+func checkType(t, v any, path string) error {
+	var types []string
+	switch x := t.(type) {
+	case string:
+		types = []string{x}
+	case []any:
+		for _, e := range x {
+			if s, ok := e.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return nil
+	}
+	for _, want := range types {
+		if hasType(want, v) {
+			return nil
+		}
+	}
+	return fail(path, fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), typeOf(v)))
+}
+
+func hasType(want string, v any) bool {
+	switch want {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	default:
+		return typeOf(v) == want
+	}
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// normalize converts json.Number values to float64 so instances and schema
+// literals compare consistently.
+func normalize(v any) any {
+	switch x := v.(type) {
+	case json.Number:
+		f, _ := x.Float64()
+		return f
+	case map[string]any:
+		for k, e := range x {
+			x[k] = normalize(e)
+		}
+	case []any:
+		for i, e := range x {
+			x[i] = normalize(e)
+		}
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func intKeyword(n map[string]any, name string) (int, bool) {
+	f, ok := numKeyword(n, name)
+	return int(f), ok
+}
+
+func numKeyword(n map[string]any, name string) (float64, bool) {
+	f, ok := n[name].(float64)
+	return f, ok
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func compact(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func errMessage(err error) string {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve.Error()
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func fail(path, msg string) error {
+	return &ValidationError{Path: path, Message: msg}
+}
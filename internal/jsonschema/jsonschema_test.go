@@ -0,0 +1,141 @@
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const personSchema = `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "age": {"type": "integer", "minimum": 0},
+    "email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+    "tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "uniqueItems": true},
+    "role": {"enum": ["admin", "user"]}
+  },
+  "required": ["name", "age"],
+  "additionalProperties": false,
+  "$defs": {
+    "tag": {"type": "string", "maxLength": 8}
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	tests := []struct {
+		doc  string
+		path string // expected failing path; "-" means valid
+		msg  string
+	}{
+		{`{"name":"Ada","age":36}`, "-", ""},
+		{` {"name":"Ada","age":36,"tags":["math","code"],"role":"admin","email":"a@b.c"} `, "-", ""},
+		{`{"name":"Ada"}`, "", `missing required property "age"`},
+		{`{"name":"Ada","age":36.5}`, "/age", "expected integer"},
+		{`{"name":"Ada","age":-1}`, "/age", "must be >= 0"},
+		{`{"name":"","age":1}`, "/name", "at least 1"},
+		{`{"name":"Ada","age":1,"extra":true}`, "", `unexpected property "extra"`},
+		{`{"name":"Ada","age":1,"tags":["toolongtag"]}`, "/tags/0", "at most 8"},
+		{`{"name":"Ada","age":1,"tags":["a","a"]}`, "/tags", "are equal"},
+		{`{"name":"Ada","age":1,"role":"root"}`, "/role", "one of"},
+		{`{"name":"Ada","age":1,"email":"nope"}`, "/email", "pattern"},
+		{`{"name":"Ada","age":1`, "", "invalid JSON"},
+		{`{"name":"Ada","age":1} trailing`, "", "invalid JSON"},
+		{`[]`, "", "expected object, got array"},
+	}
+	for _, tt := range tests {
+		err := s.Validate([]byte(tt.doc))
+		if tt.path == "-" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.doc, err)
+			}
+			continue
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("%s: expected ValidationError, got %v", tt.doc, err)
+			continue
+		}
+		if ve.Path != tt.path || !strings.Contains(ve.Message, tt.msg) {
+			t.Errorf("%s: got %q at %q, want %q at %q", tt.doc, ve.Message, ve.Path, tt.msg, tt.path)
+		}
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	s, err := Compile([]byte(`{
+  "anyOf": [{"type": "string"}, {"type": "number", "multipleOf": 5}],
+  "not": {"const": "forbidden"}
+}`))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	for doc, ok := range map[string]bool{
+		`"hello"`:     true,
+		`15`:          true,
+		`7`:           false,
+		`null`:        false,
+		`"forbidden"`: false,
+	} {
+		if err := s.Validate([]byte(doc)); (err == nil) != ok {
+			t.Errorf("%s: err=%v, want valid=%v", doc, err, ok)
+		}
+	}
+
+	one, _ := Compile([]byte(`{"oneOf": [{"type": "integer"}, {"type": "number"}]}`))
+	if err := one.Validate([]byte(`1`)); err == nil {
+		t.Error("expected oneOf to reject a value matching both schemas")
+	}
+	if err := one.Validate([]byte(`1.5`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRecursiveRef(t *testing.T) {
+	s, err := Compile([]byte(`{
+  "type": "object",
+  "properties": {"children": {"type": "array", "items": {"$ref": "#"}}},
+  "required": ["children"]
+}`))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if err := s.Validate([]byte(`{"children":[{"children":[]}]}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = s.Validate([]byte(`{"children":[{"children":[{}]}]}`))
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Path != "/children/0/children/0" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	loop, err := Compile([]byte(`{"$ref": "#"}`))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if err := loop.Validate([]byte(`{}`)); err == nil {
+		t.Error("expected self-referencing schema to fail instead of looping")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{
+		`not json`,
+		`"string"`,
+		`{"pattern": "("}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+		`{"$ref": "#/$defs/missing"}`,
+	} {
+		if _, err := Compile([]byte(src)); err == nil {
+			t.Errorf("%s: expected compile error", src)
+		}
+	}
+	if _, err := Compile([]byte(`{"enum": [{"pattern": "("}]}`)); err != nil {
+		t.Errorf("enum literals should not be compiled as schemas: %v", err)
+	}
+}
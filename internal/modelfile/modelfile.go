@@ -0,0 +1,247 @@
+// Package modelfile parses Ollama Modelfiles into structured create requests.
+package modelfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+const tripleQuote = `"""`
+
+// Modelfile is the parsed form of a Modelfile.
+type Modelfile struct {
+	From       string
+	Adapters   []string
+	Template   string
+	System     string
+	License    []string
+	Parameters map[string]any
+	Messages   []ollamaapi.ChatMessage
+}
+
+// ParseFile reads and parses the Modelfile at path.
+func ParseFile(path string) (*Modelfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	mf, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mf, nil
+}
+
+// Parse parses a Modelfile supporting FROM, PARAMETER, SYSTEM, TEMPLATE,
+// ADAPTER, LICENSE and MESSAGE. Values may be bare, "quoted" or wrapped in
+// """triple quotes""" to span multiple lines.
+func Parse(r io.Reader) (*Modelfile, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		lines = append(lines, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	mf := &Modelfile{Parameters: map[string]any{}}
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmd, rest := splitWord(line)
+
+		switch strings.ToUpper(cmd) {
+		case "FROM", "ADAPTER", "SYSTEM", "TEMPLATE", "LICENSE":
+			v, err := readValue(rest, lines, &i)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if err := mf.set(strings.ToUpper(cmd), v); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		case "PARAMETER":
+			name, raw := splitWord(rest)
+			if name == "" || strings.TrimSpace(raw) == "" {
+				return nil, fmt.Errorf("line %d: PARAMETER requires a name and a value", lineNo)
+			}
+			v, err := readValue(raw, lines, &i)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			SetParameter(mf.Parameters, strings.ToLower(name), v)
+		case "MESSAGE":
+			role, raw := splitWord(rest)
+			role = strings.ToLower(role)
+			switch role {
+			case "system", "user", "assistant", "tool":
+			default:
+				return nil, fmt.Errorf("line %d: invalid MESSAGE role %q (expected system, user, assistant or tool)", lineNo, role)
+			}
+			v, err := readValue(raw, lines, &i)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			mf.Messages = append(mf.Messages, ollamaapi.ChatMessage{Role: role, Content: v})
+		default:
+			return nil, fmt.Errorf("line %d: unknown instruction %q", lineNo, cmd)
+		}
+	}
+	if strings.TrimSpace(mf.From) == "" {
+		return nil, errors.New("missing FROM instruction")
+	}
+	return mf, nil
+}
+
+func (mf *Modelfile) set(cmd, v string) error {
+	if strings.TrimSpace(v) == "" && cmd != "SYSTEM" && cmd != "TEMPLATE" {
+		return fmt.Errorf("%s requires a value", cmd)
+	}
+	switch cmd {
+	case "FROM":
+		mf.From = strings.TrimSpace(v)
+	case "ADAPTER":
+		mf.Adapters = append(mf.Adapters, strings.TrimSpace(v))
+	case "SYSTEM":
+		mf.System = v
+	case "TEMPLATE":
+		mf.Template = v
+	case "LICENSE":
+		mf.License = append(mf.License, v)
+	}
+	return nil
+}
+
+// CreateRequest converts the Modelfile into a create request for model.
+// FROM is passed through as a model reference; callers resolve local files.
+func (mf *Modelfile) CreateRequest(model string) ollamaapi.CreateRequest {
+	req := ollamaapi.CreateRequest{
+		Model:    model,
+		From:     mf.From,
+		Template: mf.Template,
+		System:   mf.System,
+		License:  mf.License,
+		Messages: mf.Messages,
+		Stream:   true,
+	}
+	if len(mf.Parameters) > 0 {
+		req.Parameters = mf.Parameters
+	}
+	return req
+}
+
+// LocalPath resolves ref relative to dir and reports whether it names an
+// existing local file or directory rather than a model reference.
+func LocalPath(ref, dir string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false
+	}
+	if ref == "~" || strings.HasPrefix(ref, "~/") || strings.HasPrefix(ref, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			ref = filepath.Join(home, ref[1:])
+		}
+	}
+	p := ref
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	return p, true
+}
+
+// SetParameter stores a model parameter, converting the value to the JSON type
+// Ollama expects. "stop" may be given multiple times and accumulates.
+func SetParameter(params map[string]any, name, value string) {
+	if name == "stop" {
+		prev, _ := params[name].([]string)
+		params[name] = append(prev, value)
+		return
+	}
+	params[name] = ParseValue(value)
+}
+
+// ParseValue converts a textual parameter value into a number, bool or string.
+func ParseValue(v string) any {
+	v = strings.TrimSpace(v)
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	switch strings.ToLower(v) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return v
+}
+
+// splitWord splits s into its first whitespace-delimited word and the rest.
+func splitWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// readValue returns the value starting at rest, consuming further lines from
+// lines (advancing *i) when the value is a multi-line triple-quoted string.
+func readValue(rest string, lines []string, i *int) (string, error) {
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, tripleQuote) {
+		body := rest[len(tripleQuote):]
+		if end := strings.Index(body, tripleQuote); end >= 0 {
+			if strings.TrimSpace(body[end+len(tripleQuote):]) != "" {
+				return "", errors.New("unexpected text after closing \"\"\"")
+			}
+			return body[:end], nil
+		}
+		// Delimiters on their own line do not contribute blank lines.
+		var parts []string
+		if strings.TrimSpace(body) != "" {
+			parts = append(parts, body)
+		}
+		for *i+1 < len(lines) {
+			*i++
+			l := lines[*i]
+			if end := strings.Index(l, tripleQuote); end >= 0 {
+				if strings.TrimSpace(l[end+len(tripleQuote):]) != "" {
+					return "", errors.New("unexpected text after closing \"\"\"")
+				}
+				if strings.TrimSpace(l[:end]) != "" {
+					parts = append(parts, l[:end])
+				}
+				return strings.Join(parts, "\n"), nil
+			}
+			parts = append(parts, l)
+		}
+		return "", errors.New("unterminated \"\"\" string")
+	}
+	if len(rest) >= 2 && strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`) {
+		if v, err := strconv.Unquote(rest); err == nil {
+			return v, nil
+		}
+		return rest[1 : len(rest)-1], nil
+	}
+	return rest, nil
+}
@@ -0,0 +1,104 @@
+package modelfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := `# comment
+FROM llama3:8b
+PARAMETER temperature 0.2
+PARAMETER num_ctx 4096
+PARAMETER stop "<|eot|>"
+PARAMETER stop "</s>"
+SYSTEM """
+You are terse.
+Answer in one line.
+"""
+TEMPLATE """{{ .Prompt }}"""
+LICENSE MIT
+message user "hi"
+MESSAGE assistant hello there
+`
+	mf, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mf.From != "llama3:8b" {
+		t.Errorf("From = %q", mf.From)
+	}
+	if mf.System != "You are terse.\nAnswer in one line." {
+		t.Errorf("System = %q", mf.System)
+	}
+	if mf.Template != "{{ .Prompt }}" {
+		t.Errorf("Template = %q", mf.Template)
+	}
+	wantParams := map[string]any{
+		"temperature": 0.2,
+		"num_ctx":     int64(4096),
+		"stop":        []string{"<|eot|>", "</s>"},
+	}
+	if !reflect.DeepEqual(mf.Parameters, wantParams) {
+		t.Errorf("Parameters = %#v", mf.Parameters)
+	}
+	if len(mf.Messages) != 2 || mf.Messages[0].Role != "user" || mf.Messages[1].Content != "hello there" {
+		t.Errorf("Messages = %+v", mf.Messages)
+	}
+
+	req := mf.CreateRequest("mine")
+	if req.Model != "mine" || req.From != "llama3:8b" || !req.Stream || len(req.License) != 1 {
+		t.Errorf("unexpected create request: %+v", req)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing FROM":    "SYSTEM hi\n",
+		"unknown":         "FROM x\nBOGUS y\n",
+		"bad role":        "FROM x\nMESSAGE robot hi\n",
+		"parameter value": "FROM x\nPARAMETER temperature\n",
+		"unterminated":    "FROM x\nSYSTEM \"\"\"\nnever closed\n",
+		"trailing text":   "FROM x\nSYSTEM \"\"\"a\"\"\" b\n",
+		"empty FROM":      "FROM\n",
+	}
+	for name, src := range tests {
+		if _, err := Parse(strings.NewReader(src)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := LocalPath("./model.gguf", dir); !ok || p != filepath.Join(dir, "model.gguf") {
+		t.Errorf("expected local file, got %q %v", p, ok)
+	}
+	if _, ok := LocalPath("llama3:8b", dir); ok {
+		t.Errorf("model reference should not resolve to a local path")
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{"42", int64(42)},
+		{"0.5", 0.5},
+		{"true", true},
+		{"False", false},
+		{"hello", "hello"},
+	}
+	for _, tt := range tests {
+		if got := ParseValue(tt.in); got != tt.want {
+			t.Errorf("ParseValue(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ollamaapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Backend names the HTTP API a Client talks to.
+type Backend string
+
+const (
+	// BackendOllama is Ollama's native /api/* API.
+	BackendOllama Backend = "ollama"
+	// BackendOpenAI is the OpenAI-compatible /v1/* API served by vLLM, the
+	// llama.cpp server and similar. It only covers listing models, text
+	// generation, chat and embeddings.
+	BackendOpenAI Backend = "openai"
+)
+
+// ErrUnsupported is returned for operations the client's backend cannot
+// perform, such as pulling a model from an OpenAI-compatible server.
+var ErrUnsupported = errors.New("not supported by this backend")
+
+// WithBackend selects the server API. The default is BackendOllama.
+func WithBackend(b Backend) ClientOption {
+	return func(c *clientConfig) {
+		if b != "" {
+			c.backend = b
+		}
+	}
+}
+
+// backend implements the operations whose wire format differs between server
+// APIs. Client validates the arguments before calling it.
+type backend interface {
+	version(ctx context.Context) (string, error)
+	tags(ctx context.Context) ([]TagModel, error)
+	generate(ctx context.Context, req GenerateRequest, w io.Writer) (GenerateResult, error)
+	chat(ctx context.Context, req ChatRequest, w io.Writer) (ChatMessage, error)
+	embed(ctx context.Context, req EmbedRequest) ([][]float64, error)
+}
+
+func newBackend(c *Client) backend {
+	if c.kind == BackendOpenAI {
+		return openAIBackend{c: c}
+	}
+	return ollamaBackend{c: c}
+}
+
+// Backend reports the server API the client talks to.
+func (c *Client) Backend() Backend {
+	return c.kind
+}
+
+// requireOllama fails operations that only exist in Ollama's own API.
+func (c *Client) requireOllama(op string) error {
+	if c.kind == BackendOllama {
+		return nil
+	}
+	return fmt.Errorf("%s: %w (backend %s)", op, ErrUnsupported, c.kind)
+}
@@ -0,0 +1,195 @@
+package ollamaapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var digestRE = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// DigestFile returns the "sha256:<hex>" digest of the file at path, reading it
+// as a stream so large model weights are never held in memory.
+func DigestFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// BlobExists reports whether the server already has the blob with digest.
+func (c *Client) BlobExists(ctx context.Context, digest string) (bool, error) {
+	if !digestRE.MatchString(digest) {
+		return false, fmt.Errorf("blob: invalid digest %q", digest)
+	}
+	if err := c.requireOllama("check blob"); err != nil {
+		return false, err
+	}
+	path := "/api/blobs/" + digest
+	var exists bool
+	err := c.do(ctx, path, func(ctx context.Context, h *poolHost) error {
+		hreq, err := http.NewRequestWithContext(ctx, http.MethodHead, h.endpoint(path), nil)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+		resp, err := h.http.Do(hreq)
+		if err != nil {
+			return fmt.Errorf("http request: %w", err)
+		}
+		defer resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			exists = false
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			exists = true
+		default:
+			return decodeAPIError(resp, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("check blob %s: %w", digest, err)
+	}
+	return exists, nil
+}
+
+// CreateBlob uploads size bytes from r as the blob with digest. The server
+// verifies the digest, so a mismatch is reported as an APIError.
+func (c *Client) CreateBlob(ctx context.Context, digest string, r io.Reader, size int64) error {
+	if !digestRE.MatchString(digest) {
+		return fmt.Errorf("blob: invalid digest %q", digest)
+	}
+	if err := c.requireOllama("upload blob"); err != nil {
+		return err
+	}
+	path := "/api/blobs/" + digest
+	// The body can only be sent again if it can be rewound.
+	seeker, _ := r.(io.Seeker)
+	var lastErr error
+	err := c.do(ctx, path, func(ctx context.Context, h *poolHost) error {
+		if lastErr != nil {
+			if seeker == nil {
+				return lastErr
+			}
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		lastErr = c.createBlobOnce(ctx, h, path, r, size)
+		return lastErr
+	})
+	if err != nil {
+		return fmt.Errorf("upload blob %s: %w", digest, err)
+	}
+	return nil
+}
+
+func (c *Client) createBlobOnce(ctx context.Context, h *poolHost, path string, r io.Reader, size int64) error {
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint(path), io.NopCloser(r))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	hreq.ContentLength = size
+	hreq.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := h.http.Do(hreq)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeAPIError(resp, path)
+	}
+	return nil
+}
+
+// UploadFile makes the local file at path available on the server as a blob
+// and returns its digest. Files the server already has are not re-sent.
+// Status and upload progress are reported to w like pull progress.
+func (c *Client) UploadFile(ctx context.Context, path string, w io.Writer) (string, error) {
+	rep := reporterFor(w)
+	rep.Report(PullChunk{Status: "hashing " + filepath.Base(path)})
+	digest, err := DigestFile(path)
+	if err != nil {
+		return "", err
+	}
+	ok, err := c.BlobExists(ctx, digest)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		rep.Report(PullChunk{Status: "using existing blob " + digest})
+		return digest, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	pr := &progressReader{r: f, total: st.Size(), digest: digest, rep: rep, lastPct: -1}
+	if err := c.CreateBlob(ctx, digest, pr, st.Size()); err != nil {
+		return "", err
+	}
+	pr.report(true)
+	return digest, nil
+}
+
+// progressReader reports an "uploading" chunk whenever another percent of the
+// stream has been read.
+type progressReader struct {
+	r       io.Reader
+	rep     ProgressReporter
+	digest  string
+	total   int64
+	read    int64
+	lastPct int64
+}
+
+// Seek rewinds the upload for another attempt.
+func (p *progressReader) Seek(offset int64, whence int) (int64, error) {
+	s, ok := p.r.(io.Seeker)
+	if !ok {
+		return 0, errors.New("upload is not seekable")
+	}
+	n, err := s.Seek(offset, whence)
+	if err == nil {
+		p.read, p.lastPct = n, -1
+	}
+	return n, err
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	p.report(false)
+	return n, err
+}
+
+func (p *progressReader) report(final bool) {
+	pct := int64(100)
+	if p.total > 0 {
+		pct = p.read * 100 / p.total
+	}
+	if pct == p.lastPct || (!final && pct == 100) {
+		return
+	}
+	p.lastPct = pct
+	p.rep.Report(PullChunk{Status: "uploading", Digest: p.digest, Total: p.total, Completed: p.read})
+}
@@ -3,6 +3,7 @@ package ollamaapi
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -38,6 +40,9 @@ type APIError struct {
 	Status     string
 	Message    string
 	Endpoint   string
+	// Header holds the response headers, such as Retry-After. It is nil
+	// for errors reported inside a stream.
+	Header http.Header
 }
 
 func (e *APIError) Error() string {
@@ -63,9 +68,16 @@ func GetAPIError(err error) *APIError {
 }
 
 type Client struct {
-	base  *url.URL
-	http  *http.Client
-	retry RetryConfig
+	hosts    []*poolHost
+	balance  Balance
+	retry    RetryConfig
+	budget   *retryBudget
+	timeouts Timeouts
+	kind     Backend
+	backend  backend
+
+	mu sync.Mutex
+	rr int // position in the weighted rotation
 }
 
 // ClientOption allows customizing the client configuration.
@@ -80,6 +92,15 @@ type clientConfig struct {
 	maxIdleConns          int
 	maxIdleConnsPerHost   int
 	retry                 RetryConfig
+	headers               http.Header
+	tlsConfig             *tls.Config
+	backend               Backend
+	tracer                *Tracer
+	hosts                 []Host
+	balance               Balance
+	ssh                   SSHConfig
+	proxy                 proxySettings
+	timeouts              Timeouts
 }
 
 // WithDialTimeout sets a custom dial timeout.
@@ -97,6 +118,9 @@ func WithMaxIdleConns(n int) ClientOption {
 	return func(c *clientConfig) { c.maxIdleConns = n }
 }
 
+// NewClient returns a client for the Ollama API at base, an http(s) URL with
+// an optional base path, a unix:///path/to/socket URL or an
+// ssh://user@server/target URL tunneling to target through an SSH server.
 func NewClient(base *url.URL, noProxyAuto bool, opts ...ClientOption) *Client {
 	cfg := &clientConfig{
 		dialTimeout:           DefaultDialTimeout,
@@ -107,29 +131,154 @@ func NewClient(base *url.URL, noProxyAuto bool, opts ...ClientOption) *Client {
 		maxIdleConns:          DefaultMaxIdleConns,
 		maxIdleConnsPerHost:   DefaultMaxIdleConnsPerHost,
 		retry:                 NoRetry, // Disabled by default for backward compatibility
+		backend:               BackendOllama,
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
+	hosts := cfg.hosts
+	if len(hosts) == 0 {
+		hosts = []Host{{URL: base}}
+	}
+	c := &Client{
+		balance:  cfg.balance,
+		retry:    cfg.retry,
+		budget:   newRetryBudget(cfg.retry),
+		timeouts: cfg.timeouts,
+		kind:     cfg.backend,
+		rr:       newRotation(),
+	}
+	for _, h := range hosts {
+		c.hosts = append(c.hosts, newPoolHost(h, noProxyAuto, cfg))
+	}
+	c.backend = newBackend(c)
+	return c
+}
+
+// newPoolHost sets up the transport for one host.
+func newPoolHost(h Host, noProxyAuto bool, cfg *clientConfig) *poolHost {
+	base := h.URL
+	dialer := &net.Dialer{
+		Timeout:   cfg.dialTimeout,
+		KeepAlive: cfg.keepalive,
+	}
+	dial := dialer.DialContext
+	proxy := proxyFunc(noProxyAuto, base, cfg.proxy)
+	if base.Scheme == "unix" {
+		// Every connection goes to the socket; requests are addressed to
+		// http://localhost so the server sees an ordinary Host header.
+		sock := base.Path
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", sock)
+		}
+		base = &url.URL{Scheme: "http", Host: "localhost"}
+	}
+	var tunnel *sshTunnel
+	if base.Scheme == "ssh" {
+		tunnel = newSSHTunnel(base, cfg.ssh, dialer, cfg.dialTimeout)
+		dial = tunnel.DialContext
+		base = &url.URL{Scheme: "http", Host: tunnel.target}
+	}
+
 	// Avoid http.Client.Timeout for streaming endpoints.
 	t := &http.Transport{
-		Proxy: proxyFunc(noProxyAuto, base),
-		DialContext: (&net.Dialer{
-			Timeout:   cfg.dialTimeout,
-			KeepAlive: cfg.keepalive,
-		}).DialContext,
+		Proxy:                 proxy,
+		DialContext:           dial,
 		TLSHandshakeTimeout:   cfg.tlsHandshakeTimeout,
 		IdleConnTimeout:       cfg.idleConnTimeout,
 		ExpectContinueTimeout: cfg.expectContinueTimeout,
 		MaxIdleConns:          cfg.maxIdleConns,
 		MaxIdleConnsPerHost:   cfg.maxIdleConnsPerHost,
+		TLSClientConfig:       cfg.tlsConfig,
 	}
-	return &Client{base: base, http: &http.Client{Transport: t}, retry: cfg.retry}
+	var rt http.RoundTripper = t
+	// Tracing sits below the header transport so the trace shows the
+	// headers actually sent (with credentials redacted).
+	if cfg.tracer != nil {
+		rt = &traceTransport{base: rt, tracer: cfg.tracer}
+	}
+	if len(cfg.headers) > 0 {
+		rt = &headerTransport{base: rt, host: base.Host, header: cfg.headers}
+	}
+	return &poolHost{url: h.URL, base: base, http: &http.Client{Transport: rt}, transport: t, tunnel: tunnel, weight: max(h.Weight, 1)}
+}
+
+// Close releases the client's idle connections and closes any SSH
+// connections. The client must not be used afterwards.
+func (c *Client) Close() error {
+	var errs []error
+	for _, h := range c.hosts {
+		h.transport.CloseIdleConnections()
+		if h.tunnel != nil {
+			errs = append(errs, h.tunnel.Close())
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (c *Client) Version(ctx context.Context) (string, error) {
-	u := c.endpoint("/api/version")
+	return c.backend.version(ctx)
+}
+
+func (c *Client) Tags(ctx context.Context) ([]TagModel, error) {
+	return c.backend.tags(ctx)
+}
+
+// Generate streams the response text to w and returns the statistics from
+// the final chunk.
+func (c *Client) Generate(ctx context.Context, req GenerateRequest, w io.Writer) (GenerateResult, error) {
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		return GenerateResult{}, errors.New("generate: empty model")
+	}
+	// Keep prompt as-is (data), but disallow nil/empty to avoid silent interactive behavior.
+	if strings.TrimSpace(req.Prompt) == "" {
+		return GenerateResult{}, errors.New("generate: empty prompt")
+	}
+	return c.backend.generate(c.routeModel(ctx, req.Model), req, w)
+}
+
+// Chat sends a conversation, streams the assistant reply to w and returns the
+// complete assistant message so callers can extend the history.
+func (c *Client) Chat(ctx context.Context, req ChatRequest, w io.Writer) (ChatMessage, error) {
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		return ChatMessage{}, errors.New("chat: empty model")
+	}
+	if len(req.Messages) == 0 {
+		return ChatMessage{}, errors.New("chat: no messages")
+	}
+	return c.backend.chat(c.routeModel(ctx, req.Model), req, w)
+}
+
+// Embed returns one embedding vector per input, in input order.
+func (c *Client) Embed(ctx context.Context, req EmbedRequest) ([][]float64, error) {
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		return nil, errors.New("embed: empty model")
+	}
+	if len(req.Input) == 0 {
+		return nil, errors.New("embed: empty input")
+	}
+	vecs, err := c.backend.embed(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(req.Input) {
+		return nil, fmt.Errorf("embed: expected %d embeddings, got %d", len(req.Input), len(vecs))
+	}
+	return vecs, nil
+}
+
+// ollamaBackend speaks Ollama's native /api/* endpoints.
+type ollamaBackend struct {
+	c *Client
+}
+
+func (b ollamaBackend) version(ctx context.Context) (string, error) {
+	c := b.c
+	u := "/api/version"
 	var resp VersionResponse
 	if err := c.doJSON(ctx, http.MethodGet, u, nil, &resp); err != nil {
 		return "", fmt.Errorf("get version: %w", err)
@@ -140,8 +289,9 @@ func (c *Client) Version(ctx context.Context) (string, error) {
 	return resp.Version, nil
 }
 
-func (c *Client) Tags(ctx context.Context) ([]TagModel, error) {
-	u := c.endpoint("/api/tags")
+func (b ollamaBackend) tags(ctx context.Context) ([]TagModel, error) {
+	c := b.c
+	u := "/api/tags"
 	var resp TagsResponse
 	if err := c.doJSON(ctx, http.MethodGet, u, nil, &resp); err != nil {
 		return nil, fmt.Errorf("list models: %w", err)
@@ -150,7 +300,10 @@ func (c *Client) Tags(ctx context.Context) ([]TagModel, error) {
 }
 
 func (c *Client) PS(ctx context.Context) ([]PSModel, error) {
-	u := c.endpoint("/api/ps")
+	if err := c.requireOllama("list running models"); err != nil {
+		return nil, err
+	}
+	u := "/api/ps"
 	var resp PSResponse
 	if err := c.doJSON(ctx, http.MethodGet, u, nil, &resp); err != nil {
 		return nil, fmt.Errorf("list running models: %w", err)
@@ -158,33 +311,48 @@ func (c *Client) PS(ctx context.Context) ([]PSModel, error) {
 	return resp.Models, nil
 }
 
-func (c *Client) Show(ctx context.Context, name string) (map[string]any, error) {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return nil, errors.New("show: empty model name")
+func (c *Client) Show(ctx context.Context, name string) (ShowResponse, error) {
+	var resp ShowResponse
+	if err := c.show(ctx, name, &resp); err != nil {
+		return ShowResponse{}, err
 	}
-	u := c.endpoint("/api/show")
-	var resp map[string]any
-	if err := c.doJSON(ctx, http.MethodPost, u, ShowRequest{Name: name}, &resp); err != nil {
-		return nil, fmt.Errorf("show model %q: %w", name, err)
+	return resp, nil
+}
+
+// ShowRaw returns the server's /api/show response as sent, including fields
+// ShowResponse doesn't know about.
+func (c *Client) ShowRaw(ctx context.Context, name string) (json.RawMessage, error) {
+	var resp json.RawMessage
+	if err := c.show(ctx, name, &resp); err != nil {
+		return nil, err
 	}
 	return resp, nil
 }
 
-func (c *Client) Generate(ctx context.Context, req GenerateRequest, w io.Writer) error {
-	req.Model = strings.TrimSpace(req.Model)
-	if req.Model == "" {
-		return errors.New("generate: empty model")
+func (c *Client) show(ctx context.Context, name string, out any) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("show: empty model name")
 	}
-	// Keep prompt as-is (data), but disallow nil/empty to avoid silent interactive behavior.
-	if strings.TrimSpace(req.Prompt) == "" {
-		return errors.New("generate: empty prompt")
+	if err := c.requireOllama("show"); err != nil {
+		return err
 	}
-	u := c.endpoint("/api/generate")
+	u := "/api/show"
+	if err := c.doJSON(ctx, http.MethodPost, u, ShowRequest{Name: name}, out); err != nil {
+		return fmt.Errorf("show model %q: %w", name, err)
+	}
+	return nil
+}
 
+func (b ollamaBackend) generate(ctx context.Context, req GenerateRequest, w io.Writer) (GenerateResult, error) {
+	var res GenerateResult
+	c := b.c
+	u := "/api/generate"
+
+	start := time.Now()
 	h, err := c.doStream(ctx, u, req)
 	if err != nil {
-		return fmt.Errorf("generate with model %q: %w", req.Model, err)
+		return res, fmt.Errorf("generate with model %q: %w", req.Model, err)
 	}
 	defer h.Body.Close()
 
@@ -195,56 +363,285 @@ func (c *Client) Generate(ctx context.Context, req GenerateRequest, w io.Writer)
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return fmt.Errorf("generate stream decode: %w", err)
+			return res, fmt.Errorf("generate stream decode: %w", err)
 		}
 		if chunk.Error != "" {
-			return &APIError{StatusCode: 0, Message: chunk.Error, Endpoint: "/api/generate"}
+			return res, &APIError{StatusCode: 0, Message: chunk.Error, Endpoint: "/api/generate"}
 		}
 		if chunk.Response != "" {
+			if res.FirstToken == 0 {
+				res.FirstToken = time.Since(start)
+			}
 			if _, err := io.WriteString(w, chunk.Response); err != nil {
-				return fmt.Errorf("write response: %w", err)
+				return res, fmt.Errorf("write response: %w", err)
+			}
+		}
+		if chunk.Done {
+			res.Model = chunk.Model
+			res.DoneReason = chunk.DoneReason
+			res.Metrics = chunk.Metrics
+			break
+		}
+	}
+	return res, nil
+}
+
+func (b ollamaBackend) chat(ctx context.Context, req ChatRequest, w io.Writer) (ChatMessage, error) {
+	c := b.c
+	u := "/api/chat"
+
+	h, err := c.doStream(ctx, u, req)
+	if err != nil {
+		return ChatMessage{}, fmt.Errorf("chat with model %q: %w", req.Model, err)
+	}
+	defer h.Body.Close()
+
+	reply := ChatMessage{Role: "assistant"}
+	var content strings.Builder
+	dec := json.NewDecoder(h.Body)
+	for {
+		var chunk ChatChunk
+		if err := dec.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return ChatMessage{}, fmt.Errorf("chat stream decode: %w", err)
+		}
+		if chunk.Error != "" {
+			return ChatMessage{}, &APIError{StatusCode: 0, Message: chunk.Error, Endpoint: "/api/chat"}
+		}
+		if chunk.Message.Role != "" {
+			reply.Role = chunk.Message.Role
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if _, err := io.WriteString(w, chunk.Message.Content); err != nil {
+				return ChatMessage{}, fmt.Errorf("write response: %w", err)
 			}
 		}
 		if chunk.Done {
 			break
 		}
 	}
+	reply.Content = content.String()
+	return reply, nil
+}
+
+// Load asks the server to load a model into memory without generating,
+// keeping it loaded for keepAlive ("" uses the server default).
+func (c *Client) Load(ctx context.Context, model, keepAlive string) error {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return errors.New("load: empty model name")
+	}
+	if err := c.requireOllama("load"); err != nil {
+		return err
+	}
+	if err := c.scheduleModel(ctx, model, strings.TrimSpace(keepAlive)); err != nil {
+		return fmt.Errorf("load model %q: %w", model, err)
+	}
 	return nil
 }
 
+// Unload evicts a model from memory by sending an empty generate request with
+// keep_alive 0, as the upstream `ollama stop` does.
+func (c *Client) Unload(ctx context.Context, model string) error {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return errors.New("unload: empty model name")
+	}
+	if err := c.requireOllama("unload"); err != nil {
+		return err
+	}
+	if err := c.scheduleModel(ctx, model, "0"); err != nil {
+		return fmt.Errorf("unload model %q: %w", model, err)
+	}
+	return nil
+}
+
+// scheduleModel sends a generate request without a prompt, which only
+// (re)schedules the model with the given keep-alive.
+func (c *Client) scheduleModel(ctx context.Context, model, keepAlive string) error {
+	var resp GenerateChunk
+	req := GenerateRequest{Model: model, KeepAlive: keepAlive}
+	if err := c.doJSON(ctx, http.MethodPost, "/api/generate", req, &resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return &APIError{StatusCode: 0, Message: resp.Error, Endpoint: "/api/generate"}
+	}
+	return nil
+}
+
+func (b ollamaBackend) embed(ctx context.Context, req EmbedRequest) ([][]float64, error) {
+	var resp EmbedResponse
+	if err := b.c.doJSON(ctx, http.MethodPost, "/api/embed", req, &resp); err != nil {
+		return nil, fmt.Errorf("embed with model %q: %w", req.Model, err)
+	}
+	return resp.Embeddings, nil
+}
+
 func (c *Client) Pull(ctx context.Context, name string, w io.Writer) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return errors.New("pull: empty model name")
 	}
-	u := c.endpoint("/api/pull")
-
-	h, err := c.doStream(ctx, u, PullRequest{Name: name, Stream: true})
-	if err != nil {
+	if err := c.requireOllama("pull"); err != nil {
+		return err
+	}
+	if err := c.streamStatus(ctx, "/api/pull", PullRequest{Name: name, Stream: true}, w); err != nil {
 		return fmt.Errorf("pull model %q: %w", name, err)
 	}
-	defer h.Body.Close()
+	return nil
+}
 
-	dec := json.NewDecoder(h.Body)
+// Push uploads a model to its registry, streaming progress lines to w.
+// Insecure allows plain-HTTP or self-signed registries.
+func (c *Client) Push(ctx context.Context, req PushRequest, w io.Writer) error {
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		return errors.New("push: empty model name")
+	}
+	if err := c.requireOllama("push"); err != nil {
+		return err
+	}
+	req.Stream = true
+	if err := c.streamStatus(ctx, "/api/push", req, w); err != nil {
+		return fmt.Errorf("push model %q: %w", req.Model, err)
+	}
+	return nil
+}
+
+// Create creates a model from a structured create request, streaming status
+// lines to w.
+func (c *Client) Create(ctx context.Context, req CreateRequest, w io.Writer) error {
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		return errors.New("create: empty model name")
+	}
+	if strings.TrimSpace(req.From) == "" && len(req.Files) == 0 {
+		return errors.New("create: missing base model or files")
+	}
+	if err := c.requireOllama("create"); err != nil {
+		return err
+	}
+	req.Stream = true
+	if err := c.streamStatus(ctx, "/api/create", req, w); err != nil {
+		return fmt.Errorf("create model %q: %w", req.Model, err)
+	}
+	return nil
+}
+
+// resumableEndpoints are the streaming endpoints that may be re-issued after
+// the connection drops mid-stream: the server keeps partially transferred
+// blobs, so a repeated pull or push continues where the previous one stopped.
+var resumableEndpoints = map[string]bool{
+	"/api/pull": true,
+	"/api/push": true,
+}
+
+// idempotentEndpoints are the endpoints whose requests may be sent again
+// after a transient failure: they only read, or produce output without
+// changing the server's models. Delete, copy and create are missing on
+// purpose; after a gateway error there is no telling whether the change was
+// applied, and repeating it could fail or apply it twice.
+var idempotentEndpoints = map[string]bool{
+	"/api/version":         true,
+	"/api/tags":            true,
+	"/api/ps":              true,
+	"/api/show":            true,
+	"/api/generate":        true,
+	"/api/chat":            true,
+	"/api/embed":           true,
+	"/api/pull":            true,
+	"/api/push":            true,
+	"/v1/models":           true,
+	"/v1/completions":      true,
+	"/v1/chat/completions": true,
+	"/v1/embeddings":       true,
+}
+
+// canRetry reports whether a request to path that failed with err may be
+// sent again as retry number attempt+1.
+func (c *Client) canRetry(path string, attempt int, err error) bool {
+	return isIdempotent(path) && IsRetryableError(err) && attempt < c.retry.MaxRetries
+}
+
+// isIdempotent reports whether a request to path may be sent more than once.
+// Blobs are content-addressed, so checking or storing one twice is harmless.
+func isIdempotent(path string) bool {
+	return idempotentEndpoints[path] || strings.HasPrefix(path, "/api/blobs/")
+}
+
+// streamStatus posts req to path and reports the streamed status objects
+// (pull/create/push progress) to w; see ProgressReporter.
+//
+// For resumable endpoints a stream that breaks after the response started is
+// re-issued up to RetryConfig.MaxRetries times in a row; the count starts over
+// whenever a stream transfers more of a layer than any before it, so long
+// transfers survive several drops. The request timeout covers all attempts.
+func (c *Client) streamStatus(ctx context.Context, path string, req any, w io.Writer) error {
+	ctx, wd := c.watch(ctx, path, false)
+	defer wd.stop()
+	rep := reporterFor(w)
+	reached := map[string]int64{}
+	failures := 0
+	for {
+		h, err := c.doStream(ctx, path, req)
+		if err != nil {
+			return err
+		}
+		progressed, err := decodeStatus(h.Body, path, rep, reached)
+		h.Body.Close()
+		if err == nil {
+			return nil
+		}
+		if !resumableEndpoints[path] || !isStreamInterrupted(err) {
+			return err
+		}
+		if progressed {
+			failures = 0
+		}
+		if failures >= c.retry.MaxRetries {
+			return err
+		}
+		failures++
+		if rerr := rep.Report(PullChunk{Status: fmt.Sprintf("connection lost, retrying (%d/%d)", failures, c.retry.MaxRetries)}); rerr != nil {
+			return fmt.Errorf("write progress: %w", rerr)
+		}
+		if err := c.beforeRetry(ctx, failures, err); err != nil {
+			return wd.err(err)
+		}
+	}
+}
+
+// decodeStatus reports each status object read from r. reached records the
+// furthest offset seen per digest across attempts; the result reports whether
+// this stream went beyond it.
+func decodeStatus(r io.Reader, path string, rep ProgressReporter, reached map[string]int64) (bool, error) {
+	dec := json.NewDecoder(r)
+	progressed := false
 	for {
 		var chunk PullChunk
 		if err := dec.Decode(&chunk); err != nil {
 			if errors.Is(err, io.EOF) {
-				break
+				return progressed, nil
 			}
-			return fmt.Errorf("pull stream decode: %w", err)
+			return progressed, fmt.Errorf("stream decode: %w", err)
 		}
 		if chunk.Error != "" {
-			return &APIError{StatusCode: 0, Message: chunk.Error, Endpoint: "/api/pull"}
+			return progressed, &APIError{StatusCode: 0, Message: chunk.Error, Endpoint: path}
 		}
-		// Best-effort human output without trying to match full ollama progress UI.
-		if chunk.Digest != "" && chunk.Total > 0 {
-			fmt.Fprintf(w, "%s %s %d/%d\n", strings.TrimSpace(chunk.Status), chunk.Digest, chunk.Completed, chunk.Total)
-		} else if strings.TrimSpace(chunk.Status) != "" {
-			fmt.Fprintln(w, strings.TrimSpace(chunk.Status))
+		if err := rep.Report(chunk); err != nil {
+			return progressed, fmt.Errorf("write progress: %w", err)
+		}
+		if chunk.Digest != "" {
+			if prev, ok := reached[chunk.Digest]; !ok || chunk.Completed > prev {
+				reached[chunk.Digest] = chunk.Completed
+				progressed = true
+			}
 		}
 	}
-	return nil
 }
 
 // Delete removes a model from the Ollama server.
@@ -253,7 +650,10 @@ func (c *Client) Delete(ctx context.Context, name string) error {
 	if name == "" {
 		return errors.New("delete: empty model name")
 	}
-	u := c.endpoint("/api/delete")
+	if err := c.requireOllama("delete"); err != nil {
+		return err
+	}
+	u := "/api/delete"
 	if err := c.doJSON(ctx, http.MethodDelete, u, DeleteRequest{Name: name}, nil); err != nil {
 		return fmt.Errorf("delete model %q: %w", name, err)
 	}
@@ -270,48 +670,67 @@ func (c *Client) Copy(ctx context.Context, source, destination string) error {
 	if destination == "" {
 		return errors.New("copy: empty destination model name")
 	}
-	u := c.endpoint("/api/copy")
+	if err := c.requireOllama("copy"); err != nil {
+		return err
+	}
+	u := "/api/copy"
 	if err := c.doJSON(ctx, http.MethodPost, u, CopyRequest{Source: source, Destination: destination}, nil); err != nil {
 		return fmt.Errorf("copy model %q to %q: %w", source, destination, err)
 	}
 	return nil
 }
 
-func (c *Client) endpoint(path string) string {
-	u := *c.base
-	u.Path = path
-	return u.String()
+// doJSON sends a request to path and decodes the response into out. It fails
+// over between hosts and retries transient errors within the request timeout.
+func (c *Client) doJSON(ctx context.Context, method, path string, req any, out any) error {
+	return c.do(ctx, path, func(ctx context.Context, h *poolHost) error {
+		return c.doJSONOnce(ctx, h, method, path, req, out)
+	})
 }
 
-func (c *Client) doJSON(ctx context.Context, method, url string, req any, out any) error {
-	var lastErr error
-
-	for attempt := 0; attempt <= c.retry.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Wait before retry with exponential backoff.
-			backoff := calculateBackoff(attempt-1, c.retry)
-			if err := sleepWithContext(ctx, backoff); err != nil {
-				return lastErr // Return the original error, not the sleep cancellation
-			}
-		}
-
-		err := c.doJSONOnce(ctx, method, url, req, out)
+// do runs a request to path through fn, called once per attempt, with
+// failover between hosts, retries and the request timeout.
+func (c *Client) do(ctx context.Context, path string, fn func(ctx context.Context, h *poolHost) error) error {
+	ctx, wd := c.watch(ctx, path, false)
+	defer wd.stop()
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, path, func(h *poolHost) error {
+			return fn(ctx, h)
+		})
 		if err == nil {
+			c.budget.deposit()
 			return nil
 		}
-
-		lastErr = err
-
 		// Don't retry if the error isn't transient.
-		if !IsRetryableError(err) {
-			return err
+		if !c.canRetry(path, attempt, err) {
+			return wd.err(err)
+		}
+		if err := c.beforeRetry(ctx, attempt+1, err); err != nil {
+			return wd.err(err)
 		}
 	}
+}
 
-	return lastErr
+// beforeRetry waits before retry number attempt after err failed the previous
+// try. It returns the error to give up with instead when the server asks to
+// wait longer than RetryConfig.MaxRetryAfter, the retry budget is spent or
+// ctx ends.
+func (c *Client) beforeRetry(ctx context.Context, attempt int, err error) error {
+	if d, ok := retryAfter(err); ok && c.retry.MaxRetryAfter > 0 && d > c.retry.MaxRetryAfter {
+		return err
+	}
+	if !c.budget.withdraw() {
+		return fmt.Errorf("%w (retry budget exhausted)", err)
+	}
+	// Return the original error, not the sleep cancellation.
+	if sleepWithContext(ctx, calculateBackoff(attempt-1, c.retry, err)) != nil {
+		return err
+	}
+	return nil
 }
 
-func (c *Client) doJSONOnce(ctx context.Context, method, url string, req any, out any) error {
+func (c *Client) doJSONOnce(ctx context.Context, h *poolHost, method, path string, req any, out any) error {
+	url := h.endpoint(path)
 	var body io.Reader
 	if req != nil {
 		b, err := json.Marshal(req)
@@ -327,7 +746,7 @@ func (c *Client) doJSONOnce(ctx context.Context, method, url string, req any, ou
 	if req != nil {
 		hreq.Header.Set("Content-Type", "application/json")
 	}
-	resp, err := c.http.Do(hreq)
+	resp, err := h.http.Do(hreq)
 	if err != nil {
 		return fmt.Errorf("http request: %w", err)
 	}
@@ -345,17 +764,47 @@ func (c *Client) doJSONOnce(ctx context.Context, method, url string, req any, ou
 	return nil
 }
 
-func (c *Client) doStream(ctx context.Context, url string, req any) (*http.Response, error) {
+// doStream posts req and returns the response once the server has accepted
+// it. Failures before the response starts are retried like doJSON; the caller
+// decides whether a stream that breaks later may be re-issued. The timeouts
+// keep watching the body until it is closed.
+func (c *Client) doStream(ctx context.Context, path string, req any) (*http.Response, error) {
 	b, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
-	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	ctx, wd := c.watch(ctx, path, true)
+	for attempt := 0; ; attempt++ {
+		var resp *http.Response
+		err := c.send(ctx, path, func(h *poolHost) error {
+			var err error
+			resp, err = c.doStreamOnce(ctx, h, path, b)
+			return err
+		})
+		if err == nil {
+			c.budget.deposit()
+			resp.Body = &watchedBody{ReadCloser: resp.Body, w: wd}
+			return resp, nil
+		}
+		if !c.canRetry(path, attempt, err) {
+			wd.stop()
+			return nil, wd.err(err)
+		}
+		if err := c.beforeRetry(ctx, attempt+1, err); err != nil {
+			wd.stop()
+			return nil, wd.err(err)
+		}
+	}
+}
+
+func (c *Client) doStreamOnce(ctx context.Context, h *poolHost, path string, body []byte) (*http.Response, error) {
+	url := h.endpoint(path)
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	hreq.Header.Set("Content-Type", "application/json")
-	resp, err := c.http.Do(hreq)
+	resp, err := h.http.Do(hreq)
 	if err != nil {
 		return nil, fmt.Errorf("http request: %w", err)
 	}
@@ -375,15 +824,27 @@ func extractEndpoint(urlStr string) string {
 	return u.Path
 }
 
+// decodeAPIError reads the error body of a failed request. Ollama sends
+// {"error": "message"}; OpenAI-compatible servers nest it as
+// {"error": {"message": "..."}}.
 func decodeAPIError(resp *http.Response, endpoint string) *APIError {
 	var e struct {
-		Error string `json:"error"`
+		Error json.RawMessage `json:"error"`
 	}
 	_ = json.NewDecoder(resp.Body).Decode(&e)
+	var msg string
+	if json.Unmarshal(e.Error, &msg) != nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(e.Error, &nested)
+		msg = nested.Message
+	}
 	return &APIError{
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
-		Message:    strings.TrimSpace(e.Error),
+		Message:    strings.TrimSpace(msg),
 		Endpoint:   endpoint,
+		Header:     resp.Header.Clone(),
 	}
 }
@@ -2,11 +2,21 @@ package ollamaapi
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -121,7 +131,8 @@ func TestClientShow(t *testing.T) {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprint(w, `{"name":"llama3:8b","license":"MIT"}`)
+		fmt.Fprint(w, `{"license":"MIT","parameters":"num_ctx 8192","details":{"family":"llama","quantization_level":"Q4_0"},`+
+			`"model_info":{"general.architecture":"llama","llama.context_length":8192},"capabilities":["completion","tools"]}`)
 	}))
 	defer s.Close()
 
@@ -132,8 +143,14 @@ func TestClientShow(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if resp["name"] != "llama3:8b" {
-		t.Errorf("expected name=llama3:8b, got %v", resp["name"])
+	if resp.License != "MIT" || resp.Parameters != "num_ctx 8192" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.Details.Family != "llama" || resp.Details.QuantizationLevel != "Q4_0" {
+		t.Errorf("unexpected details: %+v", resp.Details)
+	}
+	if resp.ModelInfo["llama.context_length"] != float64(8192) || len(resp.Capabilities) != 2 {
+		t.Errorf("unexpected model info: %v %v", resp.ModelInfo, resp.Capabilities)
 	}
 }
 
@@ -166,7 +183,9 @@ func TestClientGenerate(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprint(w, `{"response":"Hi","done":false}`+"\n")
-		fmt.Fprint(w, `{"response":"!","done":true}`+"\n")
+		fmt.Fprint(w, `{"model":"llama3:8b","response":"!","done":true,"done_reason":"stop",`+
+			`"total_duration":5000000000,"load_duration":250000000,"prompt_eval_count":26,`+
+			`"prompt_eval_duration":130000000,"eval_count":290,"eval_duration":4000000000}`+"\n")
 	}))
 	defer s.Close()
 
@@ -174,7 +193,7 @@ func TestClientGenerate(t *testing.T) {
 	c := NewClient(u, false)
 
 	var out strings.Builder
-	err := c.Generate(context.Background(), GenerateRequest{
+	res, err := c.Generate(context.Background(), GenerateRequest{
 		Model:  "llama3:8b",
 		Prompt: "hello",
 		Stream: true,
@@ -185,6 +204,60 @@ func TestClientGenerate(t *testing.T) {
 	if out.String() != "Hi!" {
 		t.Errorf("expected 'Hi!', got %q", out.String())
 	}
+	want := Metrics{
+		TotalDuration:      5 * time.Second,
+		LoadDuration:       250 * time.Millisecond,
+		PromptEvalCount:    26,
+		PromptEvalDuration: 130 * time.Millisecond,
+		EvalCount:          290,
+		EvalDuration:       4 * time.Second,
+	}
+	if res.Metrics != want || res.DoneReason != "stop" || res.Model != "llama3:8b" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if res.FirstToken <= 0 {
+		t.Errorf("expected time to first token to be measured, got %v", res.FirstToken)
+	}
+}
+
+func TestClientGenerateRequestFields(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if raw["system"] != "be brief" || raw["keep_alive"] != "5m" || raw["format"] != "json" || raw["raw"] != true {
+			t.Errorf("unexpected request: %v", raw)
+		}
+		if opts, _ := raw["options"].(map[string]any); opts["temperature"] != 0.1 {
+			t.Errorf("unexpected options: %v", raw["options"])
+		}
+		if _, ok := raw["suffix"]; ok {
+			t.Errorf("empty suffix should be omitted: %v", raw)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"response":"{}","done":true}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	_, err := c.Generate(context.Background(), GenerateRequest{
+		Model:     "llama3:8b",
+		Prompt:    "hello",
+		System:    "be brief",
+		Raw:       true,
+		Format:    json.RawMessage(`"json"`),
+		KeepAlive: "5m",
+		Options:   map[string]any{"temperature": 0.1},
+		Stream:    true,
+	}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
 func TestClientGenerateEmptyModel(t *testing.T) {
@@ -192,7 +265,7 @@ func TestClientGenerateEmptyModel(t *testing.T) {
 	c := NewClient(u, false)
 
 	var out strings.Builder
-	err := c.Generate(context.Background(), GenerateRequest{
+	_, err := c.Generate(context.Background(), GenerateRequest{
 		Model:  "",
 		Prompt: "hello",
 	}, &out)
@@ -206,7 +279,7 @@ func TestClientGenerateEmptyPrompt(t *testing.T) {
 	c := NewClient(u, false)
 
 	var out strings.Builder
-	err := c.Generate(context.Background(), GenerateRequest{
+	_, err := c.Generate(context.Background(), GenerateRequest{
 		Model:  "llama3:8b",
 		Prompt: "   ",
 	}, &out)
@@ -226,7 +299,7 @@ func TestClientGenerateWithAPIError(t *testing.T) {
 	c := NewClient(u, false)
 
 	var out strings.Builder
-	err := c.Generate(context.Background(), GenerateRequest{
+	_, err := c.Generate(context.Background(), GenerateRequest{
 		Model:  "nonexistent",
 		Prompt: "hello",
 	}, &out)
@@ -238,6 +311,115 @@ func TestClientGenerateWithAPIError(t *testing.T) {
 	}
 }
 
+func TestClientChat(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected /api/chat, got %s", r.URL.Path)
+		}
+
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Content != "hello" {
+			t.Errorf("unexpected messages: %+v", req.Messages)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"Hi"},"done":false}`+"\n")
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"!"},"done":true}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	msg, err := c.Chat(context.Background(), ChatRequest{
+		Model: "llama3:8b",
+		Messages: []ChatMessage{
+			{Role: "system", Content: "be brief"},
+			{Role: "user", Content: "hello"},
+		},
+		Stream: true,
+	}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "Hi!" {
+		t.Errorf("expected 'Hi!', got %q", out.String())
+	}
+	if msg.Role != "assistant" || msg.Content != "Hi!" {
+		t.Errorf("unexpected reply message: %+v", msg)
+	}
+}
+
+func TestClientChatNoMessages(t *testing.T) {
+	u, _ := url.Parse("http://localhost:11434")
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	_, err := c.Chat(context.Background(), ChatRequest{Model: "llama3:8b"}, &out)
+	if err == nil {
+		t.Fatal("expected error for empty messages")
+	}
+}
+
+func TestClientEmbed(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("expected /api/embed, got %s", r.URL.Path)
+		}
+
+		var req EmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if len(req.Input) != 2 {
+			t.Errorf("expected 2 inputs, got %d", len(req.Input))
+		}
+		if req.Truncate == nil || *req.Truncate {
+			t.Errorf("expected truncate=false, got %v", req.Truncate)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"model":"nomic-embed-text","embeddings":[[0.1,0.2],[0.3,0.4]]}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	truncate := false
+	vecs, err := c.Embed(context.Background(), EmbedRequest{
+		Model:    "nomic-embed-text",
+		Input:    []string{"a", "b"},
+		Truncate: &truncate,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vecs) != 2 || vecs[1][1] != 0.4 {
+		t.Errorf("unexpected embeddings: %v", vecs)
+	}
+}
+
+func TestClientEmbedCountMismatch(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"embeddings":[[0.1]]}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	_, err := c.Embed(context.Background(), EmbedRequest{Model: "m", Input: []string{"a", "b"}})
+	if err == nil {
+		t.Fatal("expected error for embedding count mismatch")
+	}
+}
+
 func TestClientPull(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/pull" {
@@ -303,6 +485,205 @@ func TestClientPullWithAPIError(t *testing.T) {
 	}
 }
 
+func TestClientPush(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/push" {
+			t.Errorf("expected /api/push, got %s", r.URL.Path)
+		}
+
+		var req PushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.Model != "registry.local/team/llama3:8b" || !req.Insecure || !req.Stream {
+			t.Errorf("unexpected push request: %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status":"pushing","digest":"sha256:abc","total":10,"completed":10}`+"\n")
+		fmt.Fprint(w, `{"status":"success"}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	err := c.Push(context.Background(), PushRequest{Model: "registry.local/team/llama3:8b", Insecure: true}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "pushing sha256:abc 10/10") {
+		t.Errorf("expected push progress in output, got: %s", out.String())
+	}
+}
+
+func TestClientPushWithAPIError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"error":"unauthorized: authentication required"}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	err := c.Push(context.Background(), PushRequest{Model: "llama3:8b"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "authentication required") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
+
+func TestClientCreate(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/create" {
+			t.Errorf("expected /api/create, got %s", r.URL.Path)
+		}
+
+		var req CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.Model != "mine" || req.From != "llama3:8b" || req.System != "be brief" || !req.Stream {
+			t.Errorf("unexpected create request: %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status":"using existing layer"}`+"\n")
+		fmt.Fprint(w, `{"status":"success"}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	err := c.Create(context.Background(), CreateRequest{Model: "mine", From: "llama3:8b", System: "be brief"}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "success") {
+		t.Errorf("expected 'success' in output, got: %s", out.String())
+	}
+}
+
+func TestClientCreateMissingFrom(t *testing.T) {
+	u, _ := url.Parse("http://localhost:11434")
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	if err := c.Create(context.Background(), CreateRequest{Model: "mine"}, &out); err == nil {
+		t.Fatal("expected error for missing base model")
+	}
+}
+
+func TestClientUploadFile(t *testing.T) {
+	blobs := map[string][]byte{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		digest := strings.TrimPrefix(r.URL.Path, "/api/blobs/")
+		switch r.Method {
+		case http.MethodHead:
+			if _, ok := blobs[digest]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPost:
+			b, _ := io.ReadAll(r.Body)
+			sum := sha256.Sum256(b)
+			if "sha256:"+hex.EncodeToString(sum[:]) != digest {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"digest mismatch"}`)
+				return
+			}
+			blobs[digest] = b
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer s.Close()
+
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	if err := os.WriteFile(path, []byte(strings.Repeat("weights", 1000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	var out strings.Builder
+	digest, err := c.UploadFile(context.Background(), path, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := blobs[digest]; !ok {
+		t.Fatalf("expected blob %s to be uploaded", digest)
+	}
+	if !strings.Contains(out.String(), "uploading "+digest+" 7000/7000") {
+		t.Errorf("expected upload progress in output, got: %s", out.String())
+	}
+
+	out.Reset()
+	if _, err := c.UploadFile(context.Background(), path, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "using existing blob") {
+		t.Errorf("expected existing blob to be reused, got: %s", out.String())
+	}
+}
+
+func TestClientUploadFileRetried(t *testing.T) {
+	var posts int
+	var got []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			posts++
+			b, _ := io.ReadAll(r.Body)
+			if posts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			got = b
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer s.Close()
+
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	data := strings.Repeat("weights", 1000)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(1))
+	if _, err := c.UploadFile(context.Background(), path, io.Discard); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if posts != 2 || string(got) != data {
+		t.Fatalf("posts=%d, uploaded %d of %d bytes", posts, len(got), len(data))
+	}
+}
+
+func TestClientCreateBlobDigestMismatch(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"digest mismatch"}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false)
+
+	err := c.CreateBlob(context.Background(), "sha256:"+strings.Repeat("0", 64), strings.NewReader("x"), 1)
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch error, got %v", err)
+	}
+	if _, err := c.BlobExists(context.Background(), "sha256:nothex"); err == nil {
+		t.Fatal("expected error for invalid digest")
+	}
+}
+
 func TestClientHTTPError(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -351,3 +732,575 @@ func TestClientContextCancellation(t *testing.T) {
 		t.Fatal("expected timeout error")
 	}
 }
+
+func TestReadImageFile(t *testing.T) {
+	dir := t.TempDir()
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	pngPath := filepath.Join(dir, "photo.dat")
+	if err := os.WriteFile(pngPath, png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadImageFile(pngPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := base64.StdEncoding.EncodeToString(png); got != want {
+		t.Errorf("ReadImageFile = %q, want %q", got, want)
+	}
+	if _, err := DecodeImage(got); err != nil {
+		t.Errorf("DecodeImage: %v", err)
+	}
+
+	txtPath := filepath.Join(dir, "notes.png")
+	if err := os.WriteFile(txtPath, []byte("just text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadImageFile(txtPath); !errors.Is(err, ErrImageType) {
+		t.Errorf("expected ErrImageType, got %v", err)
+	}
+
+	big := append(png, make([]byte, MaxImageSize)...)
+	if err := CheckImage(big); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+	if _, err := DecodeImage(base64.StdEncoding.EncodeToString(big)); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+}
+
+// dropConnection flushes what was written so far and closes the connection
+// without finishing the chunked response, as a dropped VPN link would.
+func dropConnection(t *testing.T, w http.ResponseWriter) {
+	t.Helper()
+	w.(http.Flusher).Flush()
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Errorf("hijack: %v", err)
+		return
+	}
+	conn.Close()
+}
+
+func fastRetry(n int) ClientOption {
+	return WithRetry(RetryConfig{MaxRetries: n, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffMultiplier: 1})
+}
+
+func TestClientPullResumesAfterDrop(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprint(w, `{"status":"pulling manifest"}`+"\n")
+		switch calls {
+		case 1:
+			fmt.Fprint(w, `{"status":"pulling abc123","digest":"sha256:abc123","total":1000,"completed":800}`+"\n")
+			dropConnection(t, w)
+		case 2:
+			// A drop in the middle of an object must not surface as a decode error.
+			fmt.Fprint(w, `{"status":"pulling abc123","digest":"sha256:abc123","total":1000,"completed":900}`+"\n"+`{"status":"pul`)
+			dropConnection(t, w)
+		default:
+			fmt.Fprint(w, `{"status":"pulling abc123","digest":"sha256:abc123","total":1000,"completed":1000}`+"\n")
+			fmt.Fprint(w, `{"status":"success"}`+"\n")
+		}
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	// One retry is enough because each re-issued stream makes progress.
+	c := NewClient(u, false, fastRetry(1))
+	p := NewProgress(nil, false)
+	if err := c.Pull(context.Background(), "llama3", p); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 requests, got %d", calls)
+	}
+	var statuses []string
+	for _, l := range p.Snapshot() {
+		if l.Digest != "" && !l.Done() {
+			t.Errorf("layer not completed: %+v", l)
+		}
+		statuses = append(statuses, l.Status)
+	}
+	got := strings.Join(statuses, "|")
+	if !strings.Contains(got, "connection lost, retrying (1/1)") || !strings.HasSuffix(got, "success") {
+		t.Errorf("unexpected statuses: %s", got)
+	}
+}
+
+func TestClientPullGivesUpAfterRetries(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"status":"pulling manifest"}`+"\n")
+		dropConnection(t, w)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(2))
+	err := c.Pull(context.Background(), "llama3", io.Discard)
+	if err == nil || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+	// Statuses without layer bytes are not progress, so the budget is not reset.
+	if calls != 3 {
+		t.Fatalf("expected 3 requests, got %d", calls)
+	}
+}
+
+func TestClientStreamRetriesBeforeResponse(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":"loading"}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":"hi","done":true}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(1))
+	var out strings.Builder
+	if _, err := c.Generate(context.Background(), GenerateRequest{Model: "m", Prompt: "p"}, &out); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if calls != 2 || out.String() != "hi" {
+		t.Fatalf("calls=%d out=%q", calls, out.String())
+	}
+}
+
+func TestClientGenerateNotReissuedMidStream(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"response":"partial"}`+"\n")
+		dropConnection(t, w)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(3))
+	var out strings.Builder
+	_, err := c.Generate(context.Background(), GenerateRequest{Model: "m", Prompt: "p"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "generate stream decode") {
+		t.Fatalf("expected stream error, got %v", err)
+	}
+	if calls != 1 || out.String() != "partial" {
+		t.Fatalf("generation must not be repeated: calls=%d out=%q", calls, out.String())
+	}
+}
+
+func TestClientMutationsNotRetried(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"error":"upstream unavailable"}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(3))
+	for name, call := range map[string]func() error{
+		"delete": func() error { return c.Delete(context.Background(), "m") },
+		"copy":   func() error { return c.Copy(context.Background(), "m", "n") },
+		"create": func() error {
+			return c.Create(context.Background(), CreateRequest{Model: "m", From: "llama3"}, io.Discard)
+		},
+	} {
+		calls = 0
+		var apiErr *APIError
+		if err := call(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected the 503, got %v", name, err)
+		}
+		if calls != 1 {
+			t.Fatalf("%s was sent %d times", name, calls)
+		}
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var calls int
+	var first time.Time
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":"rate limited"}`)
+			return
+		}
+		if d := time.Since(first); d < 900*time.Millisecond {
+			t.Errorf("retried after %v, before Retry-After", d)
+		}
+		fmt.Fprint(w, `{"version":"0.5.0"}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(1))
+	if _, err := c.Version(context.Background()); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 requests, got %d", calls)
+	}
+}
+
+func TestClientRetryAfterTooLong(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, WithRetry(RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetryAfter: time.Second}))
+	_, err := c.Version(context.Background())
+	apiErr := GetAPIError(err)
+	if apiErr == nil || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 APIError, got %v", err)
+	}
+	if got := apiErr.Header.Get("Retry-After"); got != "120" {
+		t.Fatalf("Retry-After header = %q", got)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 request, got %d", calls)
+	}
+}
+
+func TestClientRetryBudget(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	cfg := RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BudgetBurst: 2}
+	c := NewClient(u, false, WithRetry(cfg))
+	_, err := c.Version(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted") || !IsAPIError(err) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 requests, got %d", calls)
+	}
+	// The budget is per client: later requests are not retried at all.
+	calls = 0
+	if _, err := c.Version(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 request, got %d", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	} {
+		err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: 429, Header: http.Header{"Retry-After": {tc.value}}})
+		got, ok := retryAfter(err)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tc.value, got, ok, tc.want, tc.ok)
+		}
+	}
+	d, ok := retryAfter(&APIError{Header: http.Header{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}})
+	if !ok || d <= 50*time.Second || d > time.Minute {
+		t.Errorf("HTTP date: got %v, %v", d, ok)
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("credentials sent to redirect target: %q", got)
+		}
+		fmt.Fprint(w, `{"version":"0.0.2"}`)
+	}))
+	defer other.Close()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Tenant") != "a" {
+			t.Errorf("missing X-Tenant header")
+		}
+		if r.URL.Path == "/api/version" {
+			http.Redirect(w, r, other.URL+"/api/version", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, `{"models":[]}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, WithHeaders(http.Header{"Authorization": {"Bearer secret"}, "X-Tenant": {"a"}}))
+	if _, err := c.Tags(context.Background()); err != nil {
+		t.Fatalf("Tags: %v", err)
+	}
+	if v, err := c.Version(context.Background()); err != nil || v != "0.0.2" {
+		t.Fatalf("Version: %q %v", v, err)
+	}
+	if _, err := NewClient(u, false).Tags(context.Background()); err == nil {
+		t.Fatal("expected 401 without headers")
+	}
+}
+
+func TestClientRelay(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"status":"pulling manifest"}`+"\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, `{"status":"success"}`+"\n")
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, WithHeaders(http.Header{"Authorization": {"Bearer secret"}}))
+	relay := httptest.NewServer(c.Relay())
+	defer relay.Close()
+
+	// A client without headers reaches the server through the relay.
+	ru, _ := url.Parse(relay.URL)
+	var out strings.Builder
+	if err := NewClient(ru, false).Pull(context.Background(), "m", &out); err != nil {
+		t.Fatalf("Pull through relay: %v", err)
+	}
+	if !strings.Contains(out.String(), "success") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			t.Errorf("expected a client certificate")
+		}
+		fmt.Fprint(w, `{"version":"0.0.1"}`)
+	}))
+	s.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	s.StartTLS()
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+
+	if _, err := NewClient(u, false).Version(context.Background()); err == nil {
+		t.Fatal("expected verification error without the server's CA")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.Certificate())
+	c := NewClient(u, false, WithTLSConfig(&tls.Config{RootCAs: roots, Certificates: s.TLS.Certificates}))
+	if _, err := c.Version(context.Background()); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	state, err := c.ConnectionState(context.Background())
+	if err != nil || state == nil {
+		t.Fatalf("ConnectionState: %v %v", state, err)
+	}
+	if len(state.PeerCertificates) == 0 || len(state.VerifiedChains) == 0 {
+		t.Fatalf("expected a verified chain, got %+v", state)
+	}
+}
+
+func TestClientBasePath(t *testing.T) {
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if !strings.HasPrefix(r.URL.Path, "/ollama/api/") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"version":"0.0.1","models":[]}`)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL + "/ollama")
+	c := NewClient(u, false)
+	if _, err := c.Version(context.Background()); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	if _, err := c.BlobExists(context.Background(), "sha256:"+strings.Repeat("0", 64)); err != nil {
+		t.Fatalf("BlobExists: %v", err)
+	}
+
+	// The relay adds the prefix for clients that address it at the root.
+	relay := httptest.NewServer(c.Relay())
+	defer relay.Close()
+	ru, _ := url.Parse(relay.URL)
+	if _, err := NewClient(ru, false).Tags(context.Background()); err != nil {
+		t.Fatalf("Tags through relay: %v", err)
+	}
+
+	want := "/ollama/api/version /ollama/api/blobs/sha256:" + strings.Repeat("0", 64) + " /ollama/api/tags"
+	if got := strings.Join(paths, " "); got != want {
+		t.Fatalf("unexpected paths: %s", got)
+	}
+}
+
+func TestClientUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "ollama.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "localhost" || r.URL.Path != "/api/version" {
+			t.Errorf("unexpected request: host=%q path=%q", r.Host, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"version":"0.0.1"}`)
+	}))
+	s.Listener = ln
+	s.Start()
+	defer s.Close()
+
+	// A proxy in the environment must not be used for socket hosts.
+	t.Setenv("HTTP_PROXY", "http://127.0.0.1:1")
+	u, _ := url.Parse("unix://" + sock)
+	c := NewClient(u, false)
+	if v, err := c.Version(context.Background()); err != nil || v != "0.0.1" {
+		t.Fatalf("Version: %q %v", v, err)
+	}
+
+	relay := httptest.NewServer(c.Relay())
+	defer relay.Close()
+	ru, _ := url.Parse(relay.URL)
+	if _, err := NewClient(ru, false).Version(context.Background()); err != nil {
+		t.Fatalf("Version through relay: %v", err)
+	}
+}
+
+func TestClientOpenAIBackend(t *testing.T) {
+	var bodies []map[string]any
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode: %v", err)
+			}
+			bodies = append(bodies, body)
+		}
+		switch r.URL.Path {
+		case "/v1/models":
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen2.5-7b","object":"model","created":1700000000}]}`)
+		case "/v1/chat/completions":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keep-alive\n\n")
+			fmt.Fprint(w, "data: {\"model\":\"qwen2.5-7b\",\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Hel\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":7,\"completion_tokens\":2}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		case "/v1/completions":
+			fmt.Fprint(w, "data: {\"choices\":[{\"text\":\"raw text\"}]}\n\ndata: [DONE]\n\n")
+		case "/v1/embeddings":
+			fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"no such route","type":"invalid_request_error"}}`)
+		}
+	}))
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, WithBackend(BackendOpenAI))
+	ctx := context.Background()
+
+	models, err := c.Tags(ctx)
+	if err != nil || len(models) != 1 || models[0].Name != "qwen2.5-7b" || models[0].ModifiedAt.Unix() != 1700000000 {
+		t.Fatalf("Tags: %+v %v", models, err)
+	}
+
+	png := base64.StdEncoding.EncodeToString(append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...))
+	var out strings.Builder
+	res, err := c.Generate(ctx, GenerateRequest{
+		Model:   "qwen2.5-7b",
+		Prompt:  "hi",
+		System:  "be brief",
+		Images:  []string{png},
+		Format:  json.RawMessage(`"json"`),
+		Options: map[string]any{"temperature": 0.2, "num_ctx": 4096, "num_predict": int64(64)},
+	}, &out)
+	if err != nil || out.String() != "Hello" {
+		t.Fatalf("Generate: %q %v", out.String(), err)
+	}
+	if res.DoneReason != "stop" || res.PromptEvalCount != 7 || res.EvalCount != 2 || res.Model != "qwen2.5-7b" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	body := bodies[0]
+	if body["temperature"] != 0.2 || body["max_tokens"] != float64(64) || body["num_ctx"] != nil {
+		t.Fatalf("options not translated: %v", body)
+	}
+	if f, _ := body["response_format"].(map[string]any); f["type"] != "json_object" {
+		t.Fatalf("unexpected response_format: %v", body["response_format"])
+	}
+	msgs, _ := body["messages"].([]any)
+	if len(msgs) != 2 {
+		t.Fatalf("expected system and user messages, got %v", msgs)
+	}
+	parts, _ := msgs[1].(map[string]any)["content"].([]any)
+	if len(parts) != 2 || !strings.HasPrefix(fmt.Sprint(parts[1]), "map[image_url:map[url:data:image/png;base64,") {
+		t.Fatalf("expected text and image parts, got %v", parts)
+	}
+
+	out.Reset()
+	raw := GenerateRequest{Model: "m", Prompt: "once upon", Raw: true, Options: map[string]any{"num_predict": int64(-1)}}
+	if _, err := c.Generate(ctx, raw, &out); err != nil || out.String() != "raw text" {
+		t.Fatalf("raw Generate: %q %v", out.String(), err)
+	}
+	if bodies[1]["prompt"] != "once upon" || bodies[1]["max_tokens"] != nil {
+		t.Fatalf("raw prompt not sent to /v1/completions: %v", bodies[1])
+	}
+
+	out.Reset()
+	reply, err := c.Chat(ctx, ChatRequest{Model: "m", Messages: []ChatMessage{{Role: "user", Content: "hi"}}}, &out)
+	if err != nil || reply.Content != "Hello" || reply.Role != "assistant" {
+		t.Fatalf("Chat: %+v %v", reply, err)
+	}
+
+	vecs, err := c.Embed(ctx, EmbedRequest{Model: "m", Input: []string{"a", "b"}})
+	if err != nil || len(vecs) != 2 || vecs[0][0] != 0.1 || vecs[1][0] != 0.3 {
+		t.Fatalf("Embed: %v %v", vecs, err)
+	}
+
+	if _, err := c.Show(ctx, "m"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := c.Pull(ctx, "m", io.Discard); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestDecodeAPIErrorNested(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"invalid api key","type":"authentication_error"}}`)
+	}))
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+	_, err := NewClient(u, false, WithBackend(BackendOpenAI)).Tags(context.Background())
+	apiErr := GetAPIError(err)
+	if apiErr == nil || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "invalid api key" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -26,6 +26,160 @@ func FormatPS(models []PSModel) string {
 	return formatTable(cols, rows)
 }
 
+// FormatShow renders a model summary in the layout of the upstream `ollama show`.
+func FormatShow(r ShowResponse) string {
+	var b strings.Builder
+	section := func(title string, rows [][]string) {
+		if len(rows) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %s\n", title)
+		width := 0
+		for _, r := range rows {
+			if len(r) > 1 && len(r[0]) > width {
+				width = len(r[0])
+			}
+		}
+		for _, r := range rows {
+			if len(r) == 1 {
+				fmt.Fprintf(&b, "    %s\n", r[0])
+				continue
+			}
+			fmt.Fprintf(&b, "    %s    %s\n", padRight(r[0], width), r[1])
+		}
+	}
+
+	arch := infoString(r.ModelInfo, "general.architecture")
+	var model [][]string
+	model = appendRow(model, "architecture", arch)
+	if n, ok := infoNumber(r.ModelInfo, "general.parameter_count"); ok {
+		model = appendRow(model, "parameters", fmtCount(n))
+	} else {
+		model = appendRow(model, "parameters", r.Details.ParameterSize)
+	}
+	if n, ok := infoNumber(r.ModelInfo, arch+".context_length"); ok {
+		model = appendRow(model, "context length", fmt.Sprintf("%.0f", n))
+	}
+	if n, ok := infoNumber(r.ModelInfo, arch+".embedding_length"); ok {
+		model = appendRow(model, "embedding length", fmt.Sprintf("%.0f", n))
+	}
+	model = appendRow(model, "quantization", r.Details.QuantizationLevel)
+	section("Model", model)
+
+	var caps [][]string
+	for _, c := range r.Capabilities {
+		caps = append(caps, []string{c})
+	}
+	section("Capabilities", caps)
+
+	if len(r.ProjectorInfo) > 0 {
+		parch := infoString(r.ProjectorInfo, "general.architecture")
+		var proj [][]string
+		proj = appendRow(proj, "architecture", parch)
+		if n, ok := infoNumber(r.ProjectorInfo, "general.parameter_count"); ok {
+			proj = appendRow(proj, "parameters", fmtCount(n))
+		}
+		if n, ok := infoNumber(r.ProjectorInfo, parch+".vision.embedding_length"); ok {
+			proj = appendRow(proj, "embedding length", fmt.Sprintf("%.0f", n))
+		}
+		if n, ok := infoNumber(r.ProjectorInfo, parch+".vision.projection_dim"); ok {
+			proj = appendRow(proj, "dimensions", fmt.Sprintf("%.0f", n))
+		}
+		section("Projector", proj)
+	}
+
+	var params [][]string
+	for _, line := range strings.Split(r.Parameters, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			params = append(params, []string{k, strings.TrimSpace(v)})
+		}
+	}
+	section("Parameters", params)
+
+	section("System", textRows(r.System, 2))
+	section("License", textRows(r.License, 2))
+	return b.String()
+}
+
+func appendRow(rows [][]string, label, value string) [][]string {
+	if strings.TrimSpace(value) == "" {
+		return rows
+	}
+	return append(rows, []string{label, value})
+}
+
+// textRows returns the first n non-empty lines of s, with "..." when truncated.
+func textRows(s string, n int) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(rows) == n {
+			return append(rows, []string{"..."})
+		}
+		rows = append(rows, []string{line})
+	}
+	return rows
+}
+
+func infoString(info map[string]any, key string) string {
+	s, _ := info[key].(string)
+	return s
+}
+
+func infoNumber(info map[string]any, key string) (float64, bool) {
+	n, ok := info[key].(float64)
+	return n, ok
+}
+
+// fmtCount abbreviates large counts the way model sizes are usually quoted (8.0B).
+func fmtCount(n float64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fB", n/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", n/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fK", n/1e3)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
+
+// FormatMetrics renders generation statistics in the layout of the upstream
+// CLI's --verbose output.
+func FormatMetrics(r GenerateResult) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "%-22s%s\n", label+":", value)
+	}
+	line("total duration", r.TotalDuration.String())
+	line("load duration", r.LoadDuration.String())
+	if r.FirstToken > 0 {
+		line("time to first token", r.FirstToken.String())
+	}
+	line("prompt eval count", fmt.Sprintf("%d token(s)", r.PromptEvalCount))
+	if r.PromptEvalDuration > 0 {
+		line("prompt eval duration", r.PromptEvalDuration.String())
+		line("prompt eval rate", fmtRate(r.PromptEvalCount, r.PromptEvalDuration))
+	}
+	line("eval count", fmt.Sprintf("%d token(s)", r.EvalCount))
+	if r.EvalDuration > 0 {
+		line("eval duration", r.EvalDuration.String())
+		line("eval rate", fmtRate(r.EvalCount, r.EvalDuration))
+	}
+	return b.String()
+}
+
+func fmtRate(tokens int, d time.Duration) string {
+	return fmt.Sprintf("%.2f tokens/s", float64(tokens)/d.Seconds())
+}
+
 func shortDigest(d string) string {
 	d = strings.TrimSpace(d)
 	if len(d) <= 12 {
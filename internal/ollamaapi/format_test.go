@@ -164,3 +164,89 @@ func TestFormatTableAlignment(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatMetrics(t *testing.T) {
+	out := FormatMetrics(GenerateResult{
+		Metrics: Metrics{
+			TotalDuration:      5 * time.Second,
+			LoadDuration:       250 * time.Millisecond,
+			PromptEvalCount:    26,
+			PromptEvalDuration: 130 * time.Millisecond,
+			EvalCount:          290,
+			EvalDuration:       4 * time.Second,
+		},
+		FirstToken: 400 * time.Millisecond,
+	})
+
+	for _, want := range []string{
+		"total duration:       5s\n",
+		"load duration:        250ms\n",
+		"time to first token:  400ms\n",
+		"prompt eval count:    26 token(s)\n",
+		"prompt eval rate:     200.00 tokens/s\n",
+		"eval count:           290 token(s)\n",
+		"eval rate:            72.50 tokens/s\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	// Missing durations must not produce division by zero.
+	if out := FormatMetrics(GenerateResult{}); strings.Contains(out, "rate") {
+		t.Errorf("unexpected rates without durations:\n%s", out)
+	}
+}
+
+func TestFormatShow(t *testing.T) {
+	out := FormatShow(ShowResponse{
+		License:    "Apache License\nVersion 2.0\n\nTERMS AND CONDITIONS",
+		Parameters: "stop                           \"<|eot_id|>\"\nnum_ctx                        8192",
+		System:     "You are terse.",
+		Details:    ModelDetails{ParameterSize: "7.6B", QuantizationLevel: "Q4_K_M"},
+		ModelInfo: map[string]any{
+			"general.architecture":    "qwen2",
+			"general.parameter_count": float64(7615616512),
+			"qwen2.context_length":    float64(32768),
+			"qwen2.embedding_length":  float64(3584),
+		},
+		ProjectorInfo: map[string]any{
+			"general.architecture":         "clip",
+			"clip.vision.projection_dim":   float64(768),
+			"clip.vision.embedding_length": float64(1024),
+		},
+		Capabilities: []string{"completion", "vision"},
+	})
+
+	want := `  Model
+    architecture        qwen2
+    parameters          7.6B
+    context length      32768
+    embedding length    3584
+    quantization        Q4_K_M
+
+  Capabilities
+    completion
+    vision
+
+  Projector
+    architecture        clip
+    embedding length    1024
+    dimensions          768
+
+  Parameters
+    stop       "<|eot_id|>"
+    num_ctx    8192
+
+  System
+    You are terse.
+
+  License
+    Apache License
+    Version 2.0
+    ...
+`
+	if out != want {
+		t.Errorf("FormatShow mismatch:\n got:\n%s\nwant:\n%s", out, want)
+	}
+}
@@ -0,0 +1,51 @@
+package ollamaapi
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// WithHeaders adds h to every request sent to the client's host, e.g. an
+// Authorization bearer token for an authenticating reverse proxy. Values
+// replace headers of the same name set by the client.
+func WithHeaders(h http.Header) ClientOption {
+	return func(c *clientConfig) { c.headers = h.Clone() }
+}
+
+// headerTransport injects fixed headers into requests for one host. Requests
+// to other hosts (after a cross-host redirect) are sent unchanged so
+// credentials never leave the configured server.
+type headerTransport struct {
+	base   http.RoundTripper
+	host   string
+	header http.Header
+}
+
+func (t *headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host != t.host {
+		return t.base.RoundTrip(r)
+	}
+	r = r.Clone(r.Context())
+	for k, v := range t.header {
+		r.Header[k] = v
+	}
+	return t.base.RoundTrip(r)
+}
+
+// Relay returns a reverse proxy to the client's host that goes through the
+// client's transport, including its headers. Wrapper mode serves it on a
+// loopback listener for the upstream CLI, which cannot send custom headers.
+// A multi-host client relays to the host requests currently go to.
+func (c *Client) Relay() http.Handler {
+	h := c.active()
+	base := *h.base
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(&url.URL{Scheme: base.Scheme, Host: base.Host, Path: base.Path})
+		},
+		Transport: h.http.Transport,
+		// Flush immediately so streamed NDJSON reaches the child as it arrives.
+		FlushInterval: -1,
+	}
+}
@@ -0,0 +1,388 @@
+package ollamaapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Balance selects how a Client with several hosts spreads its requests.
+type Balance string
+
+const (
+	// BalanceFailover sends every request to the first reachable host in
+	// the configured order.
+	BalanceFailover Balance = "failover"
+	// BalanceRoundRobin spreads generate and chat requests over the
+	// reachable hosts that have the model, in proportion to their weights.
+	// Other requests fail over like BalanceFailover.
+	BalanceRoundRobin Balance = "round-robin"
+)
+
+const (
+	// hostRetryInterval is how long a host that could not be reached is
+	// only used when every other host is down too.
+	hostRetryInterval = 30 * time.Second
+	// hostModelsTTL is how long the model list of a host is cached for
+	// round-robin.
+	hostModelsTTL = time.Minute
+)
+
+// Host is one server of a multi-host client.
+type Host struct {
+	URL *url.URL
+	// Weight is the host's share of round-robin requests (values below 1
+	// count as 1).
+	Weight int
+}
+
+// WithHosts makes the client use hosts instead of the single base URL passed
+// to NewClient. Requests go to the first reachable host and fail over to the
+// next when a host cannot be reached.
+func WithHosts(hosts ...Host) ClientOption {
+	return func(c *clientConfig) { c.hosts = hosts }
+}
+
+// WithBalance selects how requests are spread over the hosts set with
+// WithHosts. The default is BalanceFailover.
+func WithBalance(b Balance) ClientOption {
+	return func(c *clientConfig) {
+		if b != "" {
+			c.balance = b
+		}
+	}
+}
+
+// ErrNoHealthyHost is returned by SelectHost when no host answers.
+var ErrNoHealthyHost = errors.New("no reachable host")
+
+// poolHost is a host of a Client with its own transport, so credentials and
+// TLS settings are scoped to it, and its health as seen by the client.
+type poolHost struct {
+	// url is the configured URL; base is where requests are addressed,
+	// which differs for Unix sockets and SSH tunnels.
+	url       *url.URL
+	base      *url.URL
+	http      *http.Client
+	transport *http.Transport
+	tunnel    *sshTunnel
+	weight    int
+
+	mu        sync.Mutex
+	downUntil time.Time
+	models    map[string]bool
+	modelsAt  time.Time
+}
+
+// endpoint returns the URL of an API path below the host's base URL, keeping
+// any base path prefix ("https://gw.example.com/ollama" + "/api/tags").
+func (h *poolHost) endpoint(path string) string {
+	u := *h.base
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	u.RawPath = ""
+	return u.String()
+}
+
+func (h *poolHost) isDown(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return now.Before(h.downUntil)
+}
+
+func (h *poolHost) setDown(down bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if down {
+		h.downUntil = time.Now().Add(hostRetryInterval)
+	} else {
+		h.downUntil = time.Time{}
+	}
+}
+
+// HostHealth is the result of probing one host.
+type HostHealth struct {
+	URL     *url.URL
+	Weight  int
+	Latency time.Duration
+	// Err is nil when the host answered the probe successfully.
+	Err error
+}
+
+// CheckHealth probes every host concurrently and returns the results in the
+// configured order. Hosts that fail are avoided by later requests for a while.
+func (c *Client) CheckHealth(ctx context.Context) []HostHealth {
+	path := "/api/version"
+	if c.kind == BackendOpenAI {
+		path = "/v1/models"
+	}
+	out := make([]HostHealth, len(c.hosts))
+	var wg sync.WaitGroup
+	for i, h := range c.hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := c.doJSONOnce(ctx, h, http.MethodGet, path, nil, nil)
+			h.setDown(err != nil)
+			out[i] = HostHealth{URL: h.url, Weight: h.weight, Latency: time.Since(start), Err: err}
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// SelectHost returns the host a program that cannot fail over by itself
+// (such as the upstream CLI) should use: the first healthy host, or under
+// BalanceRoundRobin the next healthy host in the weighted rotation. A client
+// with a single host returns it without probing.
+func (c *Client) SelectHost(ctx context.Context) (*url.URL, error) {
+	if len(c.hosts) == 1 {
+		return c.hosts[0].url, nil
+	}
+	var healthy []*poolHost
+	var errs []error
+	for i, r := range c.CheckHealth(ctx) {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.URL.Redacted(), r.Err))
+			continue
+		}
+		healthy = append(healthy, c.hosts[i])
+	}
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrNoHealthyHost, errors.Join(errs...))
+	}
+	if c.balance == BalanceRoundRobin {
+		return healthy[c.nextWeighted(healthy)].url, nil
+	}
+	return healthy[0].url, nil
+}
+
+// routeKey carries the hosts a request should try, in order.
+type routeKey struct{}
+
+func withRoute(ctx context.Context, hosts []*poolHost) context.Context {
+	return context.WithValue(ctx, routeKey{}, hosts)
+}
+
+// candidates returns the hosts to try for a request: the route set on ctx,
+// otherwise the hosts in configured order with those recently found down
+// moved to the end.
+func (c *Client) candidates(ctx context.Context) []*poolHost {
+	if r, ok := ctx.Value(routeKey{}).([]*poolHost); ok {
+		return r
+	}
+	if len(c.hosts) == 1 {
+		return c.hosts
+	}
+	now := time.Now()
+	up := make([]*poolHost, 0, len(c.hosts))
+	var down []*poolHost
+	for _, h := range c.hosts {
+		if h.isDown(now) {
+			down = append(down, h)
+		} else {
+			up = append(up, h)
+		}
+	}
+	return append(up, down...)
+}
+
+// active returns the host requests currently go to first.
+func (c *Client) active() *poolHost {
+	return c.candidates(context.Background())[0]
+}
+
+// send calls fn with each candidate host until one can be reached. Hosts that
+// cannot be reached, or whose gateway reports the server behind it as
+// unavailable, are marked down. A gateway error only moves on to the next
+// host for requests to path that may be sent twice.
+func (c *Client) send(ctx context.Context, path string, fn func(h *poolHost) error) error {
+	pin, _ := ctx.Value(pinKey{}).(*hostPin)
+	hosts := c.candidates(ctx)
+	if pin != nil {
+		pin.mu.Lock()
+		defer pin.mu.Unlock()
+		if pin.host != nil {
+			hosts = []*poolHost{pin.host}
+		}
+	}
+	var err error
+	for _, h := range hosts {
+		err = fn(h)
+		if !isUnreachable(err) && !(isIdempotent(path) && isGatewayError(err)) {
+			h.setDown(false)
+			if pin != nil {
+				pin.host = h
+			}
+			return err
+		}
+		h.setDown(true)
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// isUnreachable reports whether err means no response came back from the
+// host, so another host may be tried.
+func isUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// isGatewayError reports whether err is a 502, 503 or 504 response: a proxy
+// in front of the host, or the host itself, says the server is unavailable.
+func isGatewayError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// pinKey carries the hostPin of a sequence of requests.
+type pinKey struct{}
+
+// hostPin holds the host a sequence of requests sticks to once one of them
+// has reached it.
+type hostPin struct {
+	mu   sync.Mutex
+	host *poolHost
+}
+
+// PinHost returns a context whose requests all go to the host that answers
+// the first of them, so steps that build on each other, such as uploading
+// blobs and then creating a model from them, reach the same server. The first
+// request still fails over between hosts.
+func (c *Client) PinHost(ctx context.Context) context.Context {
+	if len(c.hosts) == 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, pinKey{}, &hostPin{})
+}
+
+// routeModel sets the route for a generate or chat request under
+// BalanceRoundRobin: the next host in the weighted rotation among the healthy
+// hosts that have model, then the remaining hosts for failover.
+func (c *Client) routeModel(ctx context.Context, model string) context.Context {
+	if c.balance != BalanceRoundRobin || len(c.hosts) == 1 {
+		return ctx
+	}
+	if _, ok := ctx.Value(routeKey{}).([]*poolHost); ok {
+		return ctx
+	}
+	order := c.candidates(ctx)
+	now := time.Now()
+	var up []*poolHost
+	for _, h := range order {
+		if !h.isDown(now) {
+			up = append(up, h)
+		}
+	}
+	eligible := c.hostsWithModel(ctx, up, model)
+	if len(eligible) == 0 {
+		eligible = up
+	}
+	if len(eligible) == 0 {
+		return ctx
+	}
+	first := eligible[c.nextWeighted(eligible)]
+	route := []*poolHost{first}
+	for _, h := range order {
+		if h != first {
+			route = append(route, h)
+		}
+	}
+	return withRoute(ctx, route)
+}
+
+// hostsWithModel returns the hosts in hs whose model list includes model.
+func (c *Client) hostsWithModel(ctx context.Context, hs []*poolHost, model string) []*poolHost {
+	has := make([]bool, len(hs))
+	var wg sync.WaitGroup
+	for i, h := range hs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			has[i] = c.hostHasModel(ctx, h, model)
+		}()
+	}
+	wg.Wait()
+	var out []*poolHost
+	for i, h := range hs {
+		if has[i] {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func (c *Client) hostHasModel(ctx context.Context, h *poolHost, model string) bool {
+	h.mu.Lock()
+	models, at := h.models, h.modelsAt
+	h.mu.Unlock()
+	if models == nil || time.Since(at) > hostModelsTTL {
+		tags, err := c.backend.tags(withRoute(ctx, []*poolHost{h}))
+		if err != nil {
+			return false
+		}
+		models = make(map[string]bool, len(tags))
+		for _, t := range tags {
+			models[canonicalModel(t.Name)] = true
+		}
+		h.mu.Lock()
+		h.models, h.modelsAt = models, time.Now()
+		h.mu.Unlock()
+	}
+	return models[canonicalModel(model)]
+}
+
+// canonicalModel adds the implicit ":latest" tag so "llama3" matches the
+// "llama3:latest" reported by /api/tags.
+func canonicalModel(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.LastIndex(name, "/"); !strings.Contains(name[i+1:], ":") {
+		name += ":latest"
+	}
+	return name
+}
+
+// nextWeighted returns the index in hs of the next host in the client's
+// weighted rotation.
+func (c *Client) nextWeighted(hs []*poolHost) int {
+	total := 0
+	for _, h := range hs {
+		total += h.weight
+	}
+	c.mu.Lock()
+	pos := c.rr % total
+	c.rr++
+	c.mu.Unlock()
+	for i, h := range hs {
+		if pos < h.weight {
+			return i
+		}
+		pos -= h.weight
+	}
+	return 0
+}
+
+// newRotation returns a random start for the weighted rotation, so separate
+// invocations of a short-lived client do not all begin at the first host.
+func newRotation() int {
+	return rand.Intn(1 << 16)
+}
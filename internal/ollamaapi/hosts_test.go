@@ -0,0 +1,188 @@
+package ollamaapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// deadURL returns the address of a closed listener, so connections are refused.
+func deadURL(t *testing.T) *url.URL {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	return &url.URL{Scheme: "http", Host: ln.Addr().String()}
+}
+
+func TestClientFailover(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"0.5.0"}`)
+	}))
+	defer s.Close()
+
+	dead := deadURL(t)
+	live, _ := url.Parse(s.URL)
+	c := NewClient(dead, false, WithHosts(Host{URL: dead}, Host{URL: live}))
+	v, err := c.Version(context.Background())
+	if err != nil || v != "0.5.0" {
+		t.Fatalf("Version = %q, %v", v, err)
+	}
+	// The unreachable host is skipped until it is due for another try.
+	if got := c.active().url; got != live {
+		t.Fatalf("active host = %v, want %v", got, live)
+	}
+
+	// A gateway whose backend is down answers, but with 503.
+	calls := 0
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "no healthy upstream", http.StatusServiceUnavailable)
+	}))
+	defer gw.Close()
+	unavailable, _ := url.Parse(gw.URL)
+	c = NewClient(unavailable, false, WithHosts(Host{URL: unavailable}, Host{URL: live}))
+	v, err = c.Version(context.Background())
+	if err != nil || v != "0.5.0" {
+		t.Fatalf("Version = %q, %v", v, err)
+	}
+	if got := c.active().url; got != live || calls != 1 {
+		t.Fatalf("active host = %v after %d calls, want %v", got, calls, live)
+	}
+}
+
+func TestClientSelectHost(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"0.5.0"}`)
+	}))
+	defer s.Close()
+
+	dead := deadURL(t)
+	live, _ := url.Parse(s.URL)
+	c := NewClient(dead, false, WithHosts(Host{URL: dead}, Host{URL: live}))
+	u, err := c.SelectHost(context.Background())
+	if err != nil || u != live {
+		t.Fatalf("SelectHost = %v, %v", u, err)
+	}
+	health := c.CheckHealth(context.Background())
+	if len(health) != 2 || health[0].Err == nil || health[1].Err != nil {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+
+	c = NewClient(dead, false, WithHosts(Host{URL: dead}, Host{URL: deadURL(t)}))
+	if _, err := c.SelectHost(context.Background()); !errors.Is(err, ErrNoHealthyHost) {
+		t.Fatalf("expected ErrNoHealthyHost, got %v", err)
+	}
+}
+
+func TestClientRoundRobin(t *testing.T) {
+	var mu sync.Mutex
+	generated := map[string]int{}
+	tagCalls := map[string]int{}
+	server := func(name, tags string) *url.URL {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch r.URL.Path {
+			case "/api/tags":
+				tagCalls[name]++
+				fmt.Fprint(w, tags)
+			case "/api/generate":
+				generated[name]++
+				fmt.Fprint(w, `{"response":"ok","done":true}`+"\n")
+			}
+		}))
+		t.Cleanup(s.Close)
+		u, _ := url.Parse(s.URL)
+		return u
+	}
+	withModel := `{"models":[{"name":"llama3:latest"}]}`
+	a := server("a", withModel)
+	b := server("b", withModel)
+	other := server("other", `{"models":[{"name":"mistral:latest"}]}`)
+
+	c := NewClient(a, false,
+		WithHosts(Host{URL: a}, Host{URL: b, Weight: 3}, Host{URL: other}),
+		WithBalance(BalanceRoundRobin))
+	c.rr = 0
+	for i := 0; i < 8; i++ {
+		if _, err := c.Generate(context.Background(), GenerateRequest{Model: "llama3", Prompt: "p"}, io.Discard); err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+	}
+	if generated["a"] != 2 || generated["b"] != 6 || generated["other"] != 0 {
+		t.Fatalf("requests per host: %v", generated)
+	}
+	// Model lists are cached between requests.
+	if tagCalls["a"] != 1 || tagCalls["b"] != 1 {
+		t.Fatalf("tag requests per host: %v", tagCalls)
+	}
+}
+
+func TestClientPinHost(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string][]string{}
+	server := func(name string) *url.URL {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			mu.Lock()
+			requests[name] = append(requests[name], r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			switch {
+			case r.Method == http.MethodHead:
+				w.WriteHeader(http.StatusNotFound)
+			case r.URL.Path == "/api/create":
+				fmt.Fprint(w, `{"status":"success"}`+"\n")
+			default:
+				w.WriteHeader(http.StatusCreated)
+			}
+		}))
+		t.Cleanup(s.Close)
+		u, _ := url.Parse(s.URL)
+		return u
+	}
+	a, b := server("a"), server("b")
+	c := NewClient(a, false, WithHosts(Host{URL: deadURL(t)}, Host{URL: a}, Host{URL: b}))
+
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	if err := os.WriteFile(path, []byte("weights"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := c.PinHost(context.Background())
+	digest, err := c.UploadFile(ctx, path, io.Discard)
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	// Even once the host looks down, the create follows its blobs.
+	c.hosts[1].setDown(true)
+	req := CreateRequest{Model: "m", Files: map[string]string{"model.gguf": digest}}
+	if err := c.Create(ctx, req, io.Discard); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(requests["a"]) != 3 || len(requests["b"]) != 0 {
+		t.Fatalf("requests per host: %v", requests)
+	}
+}
+
+func TestCanonicalModel(t *testing.T) {
+	for in, want := range map[string]string{
+		"llama3":                  "llama3:latest",
+		"Llama3:8B":               "llama3:8b",
+		"registry:5000/org/model": "registry:5000/org/model:latest",
+	} {
+		if got := canonicalModel(in); got != want {
+			t.Errorf("canonicalModel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ollamaapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// MaxImageSize is the largest image accepted for multimodal requests.
+// Ollama keeps request bodies in memory, so huge photos are rejected locally.
+const MaxImageSize = 20 * 1024 * 1024
+
+var (
+	// ErrImageTooLarge is returned for images larger than MaxImageSize.
+	ErrImageTooLarge = errors.New("image too large")
+	// ErrImageType is returned when the content is not a supported image format.
+	ErrImageType = errors.New("unsupported image type")
+)
+
+// imageTypes are the formats vision models served by Ollama can decode.
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/webp": true,
+}
+
+// ReadImageFile reads an image from disk and returns it base64-encoded for the
+// images field of GenerateRequest. The type is sniffed from the content, not
+// the file extension.
+func ReadImageFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if st, err := f.Stat(); err == nil && st.Size() > MaxImageSize {
+		return "", fmt.Errorf("%s: %w (%d bytes, limit %d)", path, ErrImageTooLarge, st.Size(), MaxImageSize)
+	}
+	// Read one byte past the limit so files that grow or lie about their size are caught.
+	data, err := io.ReadAll(io.LimitReader(f, MaxImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := CheckImage(data); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodeImage validates a base64-encoded image as produced by ReadImageFile.
+func DecodeImage(b64 string) ([]byte, error) {
+	if base64.StdEncoding.DecodedLen(len(b64)) > MaxImageSize+2 {
+		return nil, fmt.Errorf("%w (limit %d bytes)", ErrImageTooLarge, MaxImageSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if err := CheckImage(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CheckImage enforces MaxImageSize and sniffs the content type.
+func CheckImage(data []byte) error {
+	if len(data) > MaxImageSize {
+		return fmt.Errorf("%w (%d bytes, limit %d)", ErrImageTooLarge, len(data), MaxImageSize)
+	}
+	if ct := http.DetectContentType(data); !imageTypes[ct] {
+		return fmt.Errorf("%w: %s", ErrImageType, ct)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package ollamaapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// openAIBackend speaks the OpenAI-compatible /v1/* endpoints.
+//
+// Generate uses /v1/chat/completions so the server applies the model's chat
+// template like Ollama's /api/generate does; raw prompts go to
+// /v1/completions. Ollama options without an OpenAI equivalent (num_ctx,
+// top_k, ...) and keep_alive are not sent.
+type openAIBackend struct {
+	c *Client
+}
+
+// openAIOptions maps Ollama option names to OpenAI request fields.
+var openAIOptions = map[string]string{
+	"temperature":       "temperature",
+	"top_p":             "top_p",
+	"seed":              "seed",
+	"stop":              "stop",
+	"num_predict":       "max_tokens",
+	"frequency_penalty": "frequency_penalty",
+	"presence_penalty":  "presence_penalty",
+}
+
+type openAIModelList struct {
+	Data []struct {
+		ID      string `json:"id"`
+		Created int64  `json:"created"`
+	} `json:"data"`
+}
+
+type openAIEmbeddings struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// openAIChunk is one server-sent event of a streamed completion. Chat
+// completions fill Delta, plain completions fill Text.
+type openAIChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Text  string `json:"text"`
+		Delta struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// openAIPart is an element of a multimodal message content array.
+type openAIPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+func (b openAIBackend) version(ctx context.Context) (string, error) {
+	return "", b.c.requireOllama("get version")
+}
+
+func (b openAIBackend) tags(ctx context.Context) ([]TagModel, error) {
+	var resp openAIModelList
+	if err := b.c.doJSON(ctx, http.MethodGet, "/v1/models", nil, &resp); err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	models := make([]TagModel, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		t := TagModel{Name: m.ID}
+		if m.Created > 0 {
+			t.ModifiedAt = time.Unix(m.Created, 0)
+		}
+		models = append(models, t)
+	}
+	return models, nil
+}
+
+func (b openAIBackend) generate(ctx context.Context, req GenerateRequest, w io.Writer) (GenerateResult, error) {
+	body := openAIRequest(req.Model, req.Options)
+	path := "/v1/chat/completions"
+	if req.Raw {
+		path = "/v1/completions"
+		body["prompt"] = req.Prompt
+		if req.Suffix != "" {
+			body["suffix"] = req.Suffix
+		}
+	} else {
+		var msgs []ChatMessage
+		if req.System != "" {
+			msgs = append(msgs, ChatMessage{Role: "system", Content: req.System})
+		}
+		msgs = append(msgs, ChatMessage{Role: "user", Content: req.Prompt})
+		m, err := openAIMessages(msgs, req.Images)
+		if err != nil {
+			return GenerateResult{}, fmt.Errorf("generate with model %q: %w", req.Model, err)
+		}
+		body["messages"] = m
+	}
+	if f := openAIResponseFormat(req.Format); f != nil {
+		body["response_format"] = f
+	}
+
+	start := time.Now()
+	h, err := b.c.doStream(ctx, path, body)
+	if err != nil {
+		return GenerateResult{}, fmt.Errorf("generate with model %q: %w", req.Model, err)
+	}
+	defer h.Body.Close()
+	res, _, err := decodeOpenAIStream(h.Body, path, start, w)
+	if err != nil {
+		return res, err
+	}
+	if res.Model == "" {
+		res.Model = req.Model
+	}
+	return res, nil
+}
+
+func (b openAIBackend) chat(ctx context.Context, req ChatRequest, w io.Writer) (ChatMessage, error) {
+	body := openAIRequest(req.Model, req.Options)
+	msgs, err := openAIMessages(req.Messages, nil)
+	if err != nil {
+		return ChatMessage{}, fmt.Errorf("chat with model %q: %w", req.Model, err)
+	}
+	body["messages"] = msgs
+
+	path := "/v1/chat/completions"
+	h, err := b.c.doStream(ctx, path, body)
+	if err != nil {
+		return ChatMessage{}, fmt.Errorf("chat with model %q: %w", req.Model, err)
+	}
+	defer h.Body.Close()
+	_, content, err := decodeOpenAIStream(h.Body, path, time.Now(), w)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	return ChatMessage{Role: "assistant", Content: content}, nil
+}
+
+func (b openAIBackend) embed(ctx context.Context, req EmbedRequest) ([][]float64, error) {
+	body := map[string]any{"model": req.Model, "input": req.Input, "encoding_format": "float"}
+	var resp openAIEmbeddings
+	if err := b.c.doJSON(ctx, http.MethodPost, "/v1/embeddings", body, &resp); err != nil {
+		return nil, fmt.Errorf("embed with model %q: %w", req.Model, err)
+	}
+	sort.SliceStable(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+	vecs := make([][]float64, len(resp.Data))
+	for i, d := range resp.Data {
+		vecs[i] = d.Embedding
+	}
+	return vecs, nil
+}
+
+// openAIRequest starts a streamed completion request body with the options
+// that have an OpenAI equivalent.
+func openAIRequest(model string, options map[string]any) map[string]any {
+	body := map[string]any{
+		"model":  model,
+		"stream": true,
+		// Ask for token counts in the final event for --verbose.
+		"stream_options": map[string]any{"include_usage": true},
+	}
+	for k, v := range options {
+		name, ok := openAIOptions[k]
+		if !ok {
+			continue
+		}
+		// Ollama uses a negative num_predict for "no limit".
+		if k == "num_predict" && isNegative(v) {
+			continue
+		}
+		body[name] = v
+	}
+	return body
+}
+
+func isNegative(v any) bool {
+	switch n := v.(type) {
+	case int:
+		return n < 0
+	case int64:
+		return n < 0
+	case float64:
+		return n < 0
+	}
+	return false
+}
+
+// openAIMessages converts chat messages, attaching base64 images (as in
+// GenerateRequest.Images) to the last user message as data URLs.
+func openAIMessages(msgs []ChatMessage, images []string) ([]map[string]any, error) {
+	last := -1
+	for i, m := range msgs {
+		if m.Role == "user" {
+			last = i
+		}
+	}
+	out := make([]map[string]any, 0, len(msgs))
+	for i, m := range msgs {
+		if i != last || len(images) == 0 {
+			out = append(out, map[string]any{"role": m.Role, "content": m.Content})
+			continue
+		}
+		parts := []openAIPart{{Type: "text", Text: m.Content}}
+		for _, img := range images {
+			data, err := DecodeImage(img)
+			if err != nil {
+				return nil, err
+			}
+			dataURL := "data:" + http.DetectContentType(data) + ";base64," + img
+			parts = append(parts, openAIPart{Type: "image_url", ImageURL: &openAIImageURL{URL: dataURL}})
+		}
+		out = append(out, map[string]any{"role": m.Role, "content": parts})
+	}
+	return out, nil
+}
+
+// openAIResponseFormat translates GenerateRequest.Format: "json" becomes a
+// JSON object response, a schema becomes a json_schema response format.
+func openAIResponseFormat(format json.RawMessage) any {
+	if len(format) == 0 {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(format, &s) == nil {
+		if strings.EqualFold(s, "json") {
+			return map[string]any{"type": "json_object"}
+		}
+		return nil
+	}
+	return map[string]any{
+		"type":        "json_schema",
+		"json_schema": map[string]any{"name": "response", "schema": format},
+	}
+}
+
+// decodeOpenAIStream writes the streamed completion text to w and returns the
+// statistics and the complete text.
+func decodeOpenAIStream(r io.Reader, path string, start time.Time, w io.Writer) (GenerateResult, string, error) {
+	var res GenerateResult
+	var text strings.Builder
+	err := readSSE(r, func(data []byte) error {
+		var chunk openAIChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("stream decode: %w", err)
+		}
+		if chunk.Error != nil {
+			return &APIError{StatusCode: 0, Message: chunk.Error.Message, Endpoint: path}
+		}
+		if chunk.Model != "" {
+			res.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			res.PromptEvalCount = chunk.Usage.PromptTokens
+			res.EvalCount = chunk.Usage.CompletionTokens
+		}
+		for _, c := range chunk.Choices {
+			if c.FinishReason != "" {
+				res.DoneReason = c.FinishReason
+			}
+			s := c.Delta.Content + c.Text
+			if s == "" {
+				continue
+			}
+			if res.FirstToken == 0 {
+				res.FirstToken = time.Since(start)
+			}
+			text.WriteString(s)
+			if _, err := io.WriteString(w, s); err != nil {
+				return fmt.Errorf("write response: %w", err)
+			}
+		}
+		return nil
+	})
+	res.TotalDuration = time.Since(start)
+	return res, text.String(), err
+}
+
+// readSSE calls fn with the data of each server-sent event in r until the
+// "[DONE]" sentinel or EOF. Comments and other fields are skipped.
+func readSSE(r io.Reader, fn func(data []byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		switch data {
+		case "":
+			continue
+		case "[DONE]":
+			return nil
+		}
+		if err := fn([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,400 @@
+package ollamaapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ProgressReporter receives the status chunks streamed by Pull, Push, Create
+// and UploadFile. When the writer passed to those methods implements it,
+// chunks are reported to it instead of being printed line by line.
+type ProgressReporter interface {
+	Report(PullChunk) error
+}
+
+// reporterFor returns w as a ProgressReporter, wrapping plain writers so they
+// receive one text line per chunk.
+func reporterFor(w io.Writer) ProgressReporter {
+	if r, ok := w.(ProgressReporter); ok {
+		return r
+	}
+	return lineReporter{w: w}
+}
+
+type lineReporter struct {
+	w io.Writer
+}
+
+func (l lineReporter) Report(c PullChunk) error {
+	status := strings.TrimSpace(c.Status)
+	switch {
+	case c.Digest != "" && c.Total > 0:
+		_, err := fmt.Fprintf(l.w, "%s %s %d/%d\n", status, c.Digest, c.Completed, c.Total)
+		return err
+	case status != "":
+		_, err := fmt.Fprintln(l.w, status)
+		return err
+	}
+	return nil
+}
+
+const (
+	// progressRedrawInterval throttles in-place redraws on a terminal.
+	progressRedrawInterval = 100 * time.Millisecond
+	// progressSummaryInterval is how often a layer is summarized when the
+	// output is not a terminal.
+	progressSummaryInterval = 5 * time.Second
+	// progressRateWindow is the window used to compute throughput.
+	progressRateWindow = 10 * time.Second
+	progressBarWidth   = 25
+)
+
+// ProgressLine is a snapshot of one status message or one layer transfer.
+type ProgressLine struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	// Rate is the recent throughput in bytes per second.
+	Rate float64 `json:"rate,omitempty"`
+	// ETA is the estimated time left at the current rate (0 if unknown).
+	ETA time.Duration `json:"eta,omitempty"`
+}
+
+// String renders the line as a plain summary without a bar.
+func (l ProgressLine) String() string {
+	return formatProgressLine(l, false)
+}
+
+// Done reports whether the layer has been fully transferred.
+func (l ProgressLine) Done() bool {
+	return l.Total > 0 && l.Completed >= l.Total
+}
+
+// Progress aggregates status chunks per digest. On a terminal it draws
+// in-place bars with throughput and ETA; otherwise it prints each status once
+// and a summary line per layer every few seconds and on completion.
+// A nil writer only aggregates, for callers that render Snapshot themselves.
+type Progress struct {
+	w   io.Writer
+	tty bool
+	now func() time.Time
+	// size returns the terminal's columns and rows, 0 when unknown.
+	size func() (cols, rows int)
+
+	mu       sync.Mutex
+	items    []*progressItem
+	byDigest map[string]*progressItem
+	drawn    int
+	lastDraw time.Time
+	partial  []byte
+}
+
+type progressItem struct {
+	status      string
+	digest      string
+	total       int64
+	completed   int64
+	samples     []progressSample
+	lastPrinted time.Time
+}
+
+type progressSample struct {
+	at        time.Time
+	completed int64
+}
+
+// NewProgress returns a Progress rendering to w.
+func NewProgress(w io.Writer, tty bool) *Progress {
+	return &Progress{w: w, tty: tty, now: time.Now, size: terminalSize(w), byDigest: map[string]*progressItem{}}
+}
+
+// terminalSize returns a function reporting the current size of the terminal
+// w writes to, so a resized window is picked up on the next redraw.
+func terminalSize(w io.Writer) func() (int, int) {
+	f, ok := w.(*os.File)
+	if !ok {
+		return func() (int, int) { return 0, 0 }
+	}
+	return func() (int, int) {
+		cols, rows, err := term.GetSize(int(f.Fd()))
+		if err != nil {
+			return 0, 0
+		}
+		return cols, rows
+	}
+}
+
+// Report implements ProgressReporter.
+func (p *Progress) Report(c PullChunk) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.report(c)
+}
+
+func (p *Progress) report(c PullChunk) error {
+	now := p.now()
+	status := strings.TrimSpace(c.Status)
+	if c.Digest == "" {
+		if status == "" {
+			return nil
+		}
+		// Servers repeat statuses such as "pulling manifest"; show them once.
+		if n := len(p.items); n > 0 && p.items[n-1].digest == "" && p.items[n-1].status == status {
+			return nil
+		}
+		it := &progressItem{status: status}
+		p.items = append(p.items, it)
+		if p.tty {
+			return p.redraw(now)
+		}
+		return p.printLine(it, now)
+	}
+
+	it, seen := p.byDigest[c.Digest]
+	if !seen {
+		it = &progressItem{digest: c.Digest}
+		p.byDigest[c.Digest] = it
+		p.items = append(p.items, it)
+	}
+	wasDone := it.done()
+	if status != "" {
+		it.status = status
+	}
+	if c.Total > 0 {
+		it.total = c.Total
+	}
+	// A re-issued request may restart below the previous offset.
+	if c.Completed < it.completed {
+		it.samples = nil
+	}
+	it.completed = c.Completed
+	it.addSample(now)
+
+	finished := it.done() && !wasDone
+	if p.tty {
+		if !seen || finished || now.Sub(p.lastDraw) >= progressRedrawInterval {
+			return p.redraw(now)
+		}
+		return nil
+	}
+	if !seen || finished || (!it.done() && now.Sub(it.lastPrinted) >= progressSummaryInterval) {
+		return p.printLine(it, now)
+	}
+	return nil
+}
+
+// Write treats each complete line of text as a status message, so progress
+// output from other sources (such as blob hashing) keeps its place.
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(p.partial[:i]), "\r")
+		p.partial = p.partial[i+1:]
+		if err := p.report(PullChunk{Status: line}); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Close flushes any partial line and draws the final state.
+func (p *Progress) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.partial) > 0 {
+		line := string(p.partial)
+		p.partial = nil
+		if err := p.report(PullChunk{Status: line}); err != nil {
+			return err
+		}
+	}
+	if p.tty {
+		return p.redraw(p.now())
+	}
+	return nil
+}
+
+// Snapshot returns the current state of every status and layer in the order
+// they first appeared.
+func (p *Progress) Snapshot() []ProgressLine {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]ProgressLine, 0, len(p.items))
+	for _, it := range p.items {
+		out = append(out, it.line())
+	}
+	return out
+}
+
+func (p *Progress) printLine(it *progressItem, now time.Time) error {
+	it.lastPrinted = now
+	if p.w == nil {
+		return nil
+	}
+	_, err := fmt.Fprintln(p.w, formatProgressLine(it.line(), false))
+	return err
+}
+
+// redraw moves the cursor back over the previously drawn block and rewrites
+// it. The block must fit the terminal for the cursor to get back to its top:
+// lines are cut to the width, and when there are more lines than rows the
+// completed layers are collapsed into one summary line, then the oldest lines
+// are dropped.
+func (p *Progress) redraw(now time.Time) error {
+	p.lastDraw = now
+	if p.w == nil {
+		return nil
+	}
+	cols, rows := p.size()
+	lines := p.lines(false)
+	if rows > 1 && len(lines) >= rows {
+		lines = p.lines(true)
+		if len(lines) >= rows {
+			lines = lines[len(lines)-(rows-1):]
+		}
+	}
+	var b strings.Builder
+	if p.drawn > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", p.drawn)
+	}
+	for _, l := range lines {
+		b.WriteString("\r\x1b[2K")
+		// Stay off the last column so the terminal doesn't wrap the line.
+		b.WriteString(truncateLine(l, cols-1))
+		b.WriteString("\n")
+	}
+	if len(lines) < p.drawn {
+		// Clear what is left of a longer block drawn before.
+		b.WriteString("\x1b[J")
+	}
+	p.drawn = len(lines)
+	_, err := io.WriteString(p.w, b.String())
+	return err
+}
+
+// lines renders every item with a bar. With collapse, completed layers are
+// replaced by a single line counting them, where the first of them was.
+func (p *Progress) lines(collapse bool) []string {
+	out := make([]string, 0, len(p.items))
+	summary := -1
+	var done int
+	var size int64
+	for _, it := range p.items {
+		if collapse && it.digest != "" && it.done() {
+			if summary < 0 {
+				summary = len(out)
+				out = append(out, "")
+			}
+			done++
+			size += it.total
+			continue
+		}
+		out = append(out, formatProgressLine(it.line(), true))
+	}
+	if summary >= 0 {
+		noun := "layers"
+		if done == 1 {
+			noun = "layer"
+		}
+		out[summary] = fmt.Sprintf("%d %s complete: %s", done, noun, fmtBytes(size))
+	}
+	return out
+}
+
+// truncateLine cuts s to at most width runes; width <= 0 means no limit.
+func truncateLine(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	n := 0
+	for i := range s {
+		if n == width {
+			return s[:i]
+		}
+		n++
+	}
+	return s
+}
+
+func (it *progressItem) done() bool {
+	return it.total > 0 && it.completed >= it.total
+}
+
+func (it *progressItem) addSample(now time.Time) {
+	it.samples = append(it.samples, progressSample{at: now, completed: it.completed})
+	// Keep at least two samples so a rate is available after a quiet period.
+	for len(it.samples) > 2 && now.Sub(it.samples[0].at) > progressRateWindow {
+		it.samples = it.samples[1:]
+	}
+}
+
+func (it *progressItem) line() ProgressLine {
+	l := ProgressLine{Status: it.status, Digest: it.digest, Total: it.total, Completed: it.completed}
+	if n := len(it.samples); n >= 2 && !it.done() {
+		first, last := it.samples[0], it.samples[n-1]
+		if dt := last.at.Sub(first.at).Seconds(); dt > 0 && last.completed > first.completed {
+			l.Rate = float64(last.completed-first.completed) / dt
+			if it.total > it.completed {
+				l.ETA = time.Duration(float64(it.total-it.completed) / l.Rate * float64(time.Second))
+			}
+		}
+	}
+	return l
+}
+
+// formatProgressLine renders one line, with a bar when drawing on a terminal:
+//
+//	pulling 6a0746a1ec1a:  45% ▕███████████              ▏ 1.8 GB/4.0 GB  52.0 MB/s  42s
+func formatProgressLine(l ProgressLine, bar bool) string {
+	if l.Digest == "" {
+		return l.Status
+	}
+	// Pull and push statuses already name the layer ("pulling 6a0746a1ec1a").
+	label := l.Status
+	if short := shortDigest(strings.TrimPrefix(l.Digest, "sha256:")); !strings.Contains(label, short) {
+		label = strings.TrimSpace(label + " " + short)
+	}
+	if l.Total <= 0 {
+		return label
+	}
+	pct := l.Completed * 100 / l.Total
+	if pct > 100 {
+		pct = 100
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %3d%%", label, pct)
+	if bar {
+		filled := int(pct) * progressBarWidth / 100
+		fmt.Fprintf(&b, " ▕%s%s▏", strings.Repeat("█", filled), strings.Repeat(" ", progressBarWidth-filled))
+	}
+	if l.Done() {
+		fmt.Fprintf(&b, " %s", fmtBytes(l.Total))
+		return b.String()
+	}
+	fmt.Fprintf(&b, " %s/%s", fmtBytes(l.Completed), fmtBytes(l.Total))
+	if l.Rate > 0 {
+		fmt.Fprintf(&b, "  %s/s", fmtBytes(int64(l.Rate)))
+	}
+	if l.ETA > 0 {
+		if bar {
+			fmt.Fprintf(&b, "  %s", l.ETA.Round(time.Second))
+		} else {
+			fmt.Fprintf(&b, "  ETA %s", l.ETA.Round(time.Second))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package ollamaapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+const testDigest = "sha256:6a0746a1ec1aef3e7ec53868f220ff6e389f6f8ef87a01d77c96807de94ca2aa"
+
+func newTestProgress(tty bool) (*Progress, *strings.Builder, *fakeClock) {
+	var out strings.Builder
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := NewProgress(&out, tty)
+	p.now = clock.now
+	return p, &out, clock
+}
+
+func TestProgressSummaryLines(t *testing.T) {
+	p, out, clock := newTestProgress(false)
+
+	p.Report(PullChunk{Status: "pulling manifest"})
+	p.Report(PullChunk{Status: "pulling manifest"})
+	p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 1000 * 1024 * 1024})
+	// Chunks within the summary interval are aggregated silently.
+	for i := 1; i <= 4; i++ {
+		clock.advance(time.Second)
+		p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 1000 * 1024 * 1024, Completed: int64(i) * 50 * 1024 * 1024})
+	}
+	clock.advance(time.Second)
+	p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 1000 * 1024 * 1024, Completed: 250 * 1024 * 1024})
+	p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 1000 * 1024 * 1024, Completed: 1000 * 1024 * 1024})
+	p.Report(PullChunk{Status: "success"})
+	p.Close()
+
+	want := "pulling manifest\n" +
+		"pulling 6a0746a1ec1a:   0% 0 B/1000.0 MB\n" +
+		"pulling 6a0746a1ec1a:  25% 250.0 MB/1000.0 MB  50.0 MB/s  ETA 15s\n" +
+		"pulling 6a0746a1ec1a: 100% 1000.0 MB\n" +
+		"success\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestProgressTTYRedraw(t *testing.T) {
+	p, out, clock := newTestProgress(true)
+
+	p.Report(PullChunk{Status: "pulling manifest"})
+	p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 100})
+	clock.advance(10 * time.Millisecond)
+	// Throttled: too soon after the previous redraw.
+	p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 100, Completed: 10})
+	before := out.Len()
+	clock.advance(time.Second)
+	p.Report(PullChunk{Status: "pulling 6a0746a1ec1a", Digest: testDigest, Total: 100, Completed: 50})
+	last := out.String()[before:]
+
+	if !strings.HasPrefix(last, "\x1b[2A\r\x1b[2Kpulling manifest\n\r\x1b[2Kpulling 6a0746a1ec1a:  50% ▕████████████             ▏ 50 B/100 B") {
+		t.Errorf("unexpected redraw: %q", last)
+	}
+	if strings.Contains(out.String(), "10 B/100 B") {
+		t.Errorf("expected the update 10ms after a redraw to be throttled, got %q", out.String())
+	}
+}
+
+func TestProgressTTYFitsTerminal(t *testing.T) {
+	p, out, clock := newTestProgress(true)
+	p.size = func() (int, int) { return 30, 4 }
+
+	p.Report(PullChunk{Status: "pulling manifest"})
+	for _, d := range []string{"sha256:aaaaaaaaaaaa", "sha256:bbbbbbbbbbbb", "sha256:cccccccccccc"} {
+		p.Report(PullChunk{Status: "pulling " + d[7:], Digest: d, Total: 100, Completed: 100})
+	}
+	p.Report(PullChunk{Status: "pulling dddddddddddd", Digest: "sha256:dddddddddddd", Total: 100, Completed: 40})
+	before := out.Len()
+	clock.advance(time.Second)
+	p.Report(PullChunk{Status: "pulling dddddddddddd", Digest: "sha256:dddddddddddd", Total: 100, Completed: 60})
+	last := out.String()[before:]
+
+	want := "\x1b[3A" +
+		"\r\x1b[2Kpulling manifest\n" +
+		"\r\x1b[2K3 layers complete: 300 B\n" +
+		"\r\x1b[2Kpulling dddddddddddd:  60% ▕█\n"
+	if last != want {
+		t.Errorf("unexpected redraw:\n%q\nwant:\n%q", last, want)
+	}
+}
+
+func TestProgressSnapshotAndWrite(t *testing.T) {
+	p := NewProgress(nil, false)
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	p.now = clock.now
+
+	p.Write([]byte("hashing model.gguf\nusing existing"))
+	p.Report(PullChunk{Status: "uploading", Digest: testDigest, Total: 400, Completed: 100})
+	clock.advance(2 * time.Second)
+	p.Report(PullChunk{Status: "uploading", Digest: testDigest, Total: 400, Completed: 200})
+	p.Close()
+
+	lines := p.Snapshot()
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %+v", lines)
+	}
+	if lines[0].Status != "hashing model.gguf" || lines[2].Status != "using existing" {
+		t.Errorf("unexpected status lines: %+v", lines)
+	}
+	if l := lines[1]; l.Rate != 50 || l.ETA != 4*time.Second || l.Done() {
+		t.Errorf("unexpected layer: %+v", l)
+	}
+
+	// A restarted transfer resets the rate instead of reporting a negative one.
+	p.Report(PullChunk{Status: "uploading", Digest: testDigest, Total: 400, Completed: 50})
+	if l := p.Snapshot()[1]; l.Rate != 0 || l.Completed != 50 {
+		t.Errorf("unexpected layer after restart: %+v", l)
+	}
+}
@@ -1,25 +1,149 @@
 package ollamaapi
 
 import (
+	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 )
 
-func proxyFunc(noProxyAuto bool, base *url.URL) func(*http.Request) (*url.URL, error) {
-	if !noProxyAuto || base == nil {
-		return http.ProxyFromEnvironment
+// proxySettings are the proxy options of a client.
+type proxySettings struct {
+	// url replaces the proxy from the environment when set.
+	url *url.URL
+	// bypass lists hosts reached directly (see matchNoProxy).
+	bypass []string
+}
+
+// WithProxy sends requests through proxy, an http, https, socks5 or socks5h
+// URL, instead of the proxy from HTTP_PROXY/HTTPS_PROXY. A nil proxy keeps
+// the environment's. Hosts matching an entry of noProxy or of NO_PROXY are
+// always reached directly, as they are by the upstream CLI, which gets the
+// proxy through HTTP_PROXY/HTTPS_PROXY.
+func WithProxy(proxy *url.URL, noProxy []string) ClientOption {
+	return func(c *clientConfig) {
+		c.proxy = proxySettings{url: proxy, bypass: noProxy}
+	}
+}
+
+func proxyFunc(noProxyAuto bool, base *url.URL, ps proxySettings) func(*http.Request) (*url.URL, error) {
+	if base != nil && (base.Scheme == "unix" || base.Scheme == "ssh") {
+		// Socket connections are local and tunneled ones leave through
+		// the SSH server; an HTTP proxy cannot reach either.
+		return nil
+	}
+	proxy := http.ProxyFromEnvironment
+	bypass := ps.bypass
+	if ps.url != nil {
+		// The environment's NO_PROXY still applies, as it does for the
+		// upstream CLI that is handed this proxy.
+		bypass = append(envNoProxy(), bypass...)
+		fixed := ps.url
+		proxy = func(r *http.Request) (*url.URL, error) {
+			// Like the environment's proxy, never for the local machine.
+			if h := r.URL.Hostname(); strings.EqualFold(h, "localhost") || net.ParseIP(h).IsLoopback() {
+				return nil, nil
+			}
+			return fixed, nil
+		}
 	}
-	baseHost := strings.ToLower(strings.TrimSpace(base.Hostname()))
-	if baseHost == "" {
-		return http.ProxyFromEnvironment
+	if noProxyAuto && base != nil {
+		// Bypass proxies for the configured host without mutating NO_PROXY.
+		if h := strings.TrimSpace(base.Hostname()); h != "" {
+			bypass = append(bypass[:len(bypass):len(bypass)], h)
+		}
+	}
+	if len(bypass) == 0 {
+		return proxy
 	}
 	return func(r *http.Request) (*url.URL, error) {
-		h := strings.ToLower(strings.TrimSpace(r.URL.Hostname()))
-		if h != "" && h == baseHost {
-			// Bypass proxies for the configured host without mutating NO_PROXY.
+		if matchNoProxy(bypass, r.URL) {
 			return nil, nil
 		}
-		return http.ProxyFromEnvironment(r)
+		return proxy(r)
+	}
+}
+
+// envNoProxy returns the entries of NO_PROXY, or of no_proxy when it is unset.
+func envNoProxy() []string {
+	v := os.Getenv("NO_PROXY")
+	if v == "" {
+		v = os.Getenv("no_proxy")
+	}
+	var out []string
+	for _, e := range strings.Split(v, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// matchNoProxy reports whether u's host matches a bypass entry: a host name
+// (which also covers its subdomains), ".domain" or "*.domain" (subdomains
+// only), an IP address, a CIDR range, any of these followed by ":port" to
+// match only that port, or "*" for every host.
+func matchNoProxy(entries []string, u *url.URL) bool {
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := net.ParseIP(host)
+	for _, e := range entries {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(e); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(e); err == nil {
+			if p != port {
+				continue
+			}
+			e = h
+		}
+		e = strings.TrimSuffix(strings.TrimPrefix(e, "["), "]")
+		if eip := net.ParseIP(e); eip != nil {
+			if ip != nil && eip.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(e, "*"); ok {
+			e = suffix
+		}
+		if strings.HasPrefix(e, ".") {
+			if strings.HasSuffix(host, e) {
+				return true
+			}
+			continue
+		}
+		if host == e || strings.HasSuffix(host, "."+e) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProxyURL returns the proxy that requests to the active host go through, or
+// nil when they connect directly.
+func (c *Client) ProxyURL() (*url.URL, error) {
+	h := c.active()
+	if h.transport.Proxy == nil {
+		return nil, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, h.endpoint("/"), nil)
+	if err != nil {
+		return nil, err
 	}
+	return h.transport.Proxy(req)
 }
@@ -47,7 +47,7 @@ func TestProxyFunc(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			baseURL, _ := url.Parse(tt.baseHost)
-			pf := proxyFunc(tt.noProxyAuto, baseURL)
+			pf := proxyFunc(tt.noProxyAuto, baseURL, proxySettings{})
 
 			req, _ := http.NewRequest("GET", tt.requestHost, nil)
 			proxyURL, err := pf(req)
@@ -69,7 +69,7 @@ func TestProxyFunc(t *testing.T) {
 
 func TestProxyFuncNilBase(t *testing.T) {
 	// nil base should return ProxyFromEnvironment
-	pf := proxyFunc(true, nil)
+	pf := proxyFunc(true, nil, proxySettings{})
 	if pf == nil {
 		t.Fatal("expected non-nil proxy func")
 	}
@@ -77,8 +77,73 @@ func TestProxyFuncNilBase(t *testing.T) {
 
 func TestProxyFuncEmptyHost(t *testing.T) {
 	baseURL, _ := url.Parse("http:///path") // empty host
-	pf := proxyFunc(true, baseURL)
+	pf := proxyFunc(true, baseURL, proxySettings{})
 	if pf == nil {
 		t.Fatal("expected non-nil proxy func")
 	}
 }
+
+func TestMatchNoProxy(t *testing.T) {
+	entries := []string{"gpu.lab", "*.corp", ".internal", "10.0.0.0/8", "build:8080", "[fe80::1]"}
+	for raw, want := range map[string]bool{
+		"http://gpu.lab:11434":       true,
+		"http://a.gpu.lab:11434":     true,
+		"http://xgpu.lab:11434":      false,
+		"http://ollama.corp":         true,
+		"http://corp":                false,
+		"http://x.internal":          true,
+		"http://10.1.2.3:11434":      true,
+		"http://11.1.2.3:11434":      false,
+		"http://build:8080":          true,
+		"http://build:11434":         false,
+		"http://[fe80::1]:11434":     true,
+		"https://GPU.LAB./api/tags":  true,
+		"https://example.com/api/ps": false,
+	} {
+		u, _ := url.Parse(raw)
+		if got := matchNoProxy(entries, u); got != want {
+			t.Errorf("matchNoProxy(%s) = %v, want %v", raw, got, want)
+		}
+	}
+	u, _ := url.Parse("http://anything")
+	if !matchNoProxy([]string{"*"}, u) {
+		t.Error(`"*" should match every host`)
+	}
+}
+
+func TestProxyFuncExplicit(t *testing.T) {
+	proxy, _ := url.Parse("socks5://proxy.corp:1080")
+	base, _ := url.Parse("http://gpu.lab:11434")
+	pf := proxyFunc(false, base, proxySettings{url: proxy, bypass: []string{"10.0.0.0/8"}})
+	for raw, want := range map[string]*url.URL{
+		"http://gpu.lab:11434/api/tags":  proxy,
+		"http://10.0.0.5:11434/api/tags": nil,
+		"http://127.0.0.1:11434/":        nil,
+	} {
+		req, _ := http.NewRequest("GET", raw, nil)
+		got, err := pf(req)
+		if err != nil || got != want {
+			t.Errorf("%s: proxy = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+}
+
+func TestProxyFuncExplicitEnvNoProxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "gpu.lab, 192.168.0.0/16")
+	proxy, _ := url.Parse("http://proxy.corp:3128")
+	pf := proxyFunc(false, nil, proxySettings{url: proxy, bypass: []string{"*.internal"}})
+	for raw, want := range map[string]*url.URL{
+		"http://gpu.lab:11434/api/tags":    nil,
+		"http://192.168.1.20:11434/":       nil,
+		"http://ollama.internal/api/tags":  nil,
+		"http://ollama.example.com/api/ps": proxy,
+		"https://other.example.com/api/ps": proxy,
+	} {
+		req, _ := http.NewRequest("GET", raw, nil)
+		got, err := pf(req)
+		if err != nil || got != want {
+			t.Errorf("%s: proxy = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+}
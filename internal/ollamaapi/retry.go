@@ -3,9 +3,14 @@ package ollamaapi
 import (
 	"context"
 	"errors"
+	"io"
 	"math/rand"
 	"net"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -22,6 +27,16 @@ type RetryConfig struct {
 	BackoffMultiplier float64
 	// Jitter adds randomness to backoff to prevent thundering herd.
 	Jitter bool
+	// MaxRetryAfter is the longest Retry-After delay the client waits for;
+	// a server asking for more fails the request at once (0 = no limit).
+	MaxRetryAfter time.Duration
+	// BudgetRatio is the number of retries earned by each successful
+	// request. BudgetBurst caps the retries that can be saved up; a Client
+	// starts with a full budget. Retries stop while the budget is empty, so
+	// a failing server is not hit with MaxRetries times the usual load.
+	// A zero BudgetBurst disables the budget.
+	BudgetRatio float64
+	BudgetBurst int
 }
 
 // DefaultRetryConfig provides sensible defaults for retry behavior.
@@ -31,6 +46,9 @@ var DefaultRetryConfig = RetryConfig{
 	MaxBackoff:        5 * time.Second,
 	BackoffMultiplier: 2.0,
 	Jitter:            true,
+	MaxRetryAfter:     time.Minute,
+	BudgetRatio:       0.2,
+	BudgetBurst:       10,
 }
 
 // NoRetry disables retry behavior.
@@ -56,13 +74,19 @@ func IsRetryableError(err error) bool {
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false
 	}
+	// Neither requests that ran into one of the client's timeouts.
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return false
+	}
 
 	// Check for API errors with specific status codes.
 	var apiErr *APIError
 	if errors.As(err, &apiErr) {
-		// Retry on server errors (5xx) but not client errors (4xx).
+		// Retry on server errors (5xx) and rate limiting, but not other
+		// client errors (4xx).
 		switch apiErr.StatusCode {
-		case 500, 502, 503, 504:
+		case 429, 500, 502, 503, 504:
 			return true
 		default:
 			return false
@@ -100,6 +124,15 @@ func IsRetryableError(err error) bool {
 	return false
 }
 
+// isStreamInterrupted reports whether a streaming response broke off after it
+// started, as opposed to the server sending an error or malformed data.
+func isStreamInterrupted(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || IsRetryableError(err)
+}
+
 // isRetryableSyscallError checks for common retryable syscall errors.
 func isRetryableSyscallError(err error) bool {
 	if err == nil {
@@ -161,8 +194,13 @@ func equalFoldSlice(a, b string) bool {
 	return true
 }
 
-// calculateBackoff computes the next backoff duration with optional jitter.
-func calculateBackoff(attempt int, cfg RetryConfig) time.Duration {
+// calculateBackoff computes the wait before retrying after err. A
+// Retry-After sent with err is used as is; otherwise the backoff grows with
+// attempt, with optional jitter.
+func calculateBackoff(attempt int, cfg RetryConfig, err error) time.Duration {
+	if d, ok := retryAfter(err); ok {
+		return d
+	}
 	if attempt <= 0 {
 		return cfg.InitialBackoff
 	}
@@ -185,6 +223,71 @@ func calculateBackoff(attempt int, cfg RetryConfig) time.Duration {
 	return time.Duration(backoff)
 }
 
+// retryAfter returns the delay requested by the Retry-After header of an
+// APIError, given either in seconds or as an HTTP date.
+func retryAfter(err error) (time.Duration, bool) {
+	apiErr := GetAPIError(err)
+	if apiErr == nil {
+		return 0, false
+	}
+	v := strings.TrimSpace(apiErr.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(t), 0), true
+}
+
+// retryBudget is a token bucket shared by all requests of a Client: a retry
+// takes a token and a successful request puts back a fraction of one.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+	ratio  float64
+	burst  float64
+}
+
+func newRetryBudget(cfg RetryConfig) *retryBudget {
+	if cfg.BudgetBurst <= 0 {
+		return nil
+	}
+	b := float64(cfg.BudgetBurst)
+	return &retryBudget{tokens: b, ratio: cfg.BudgetRatio, burst: b}
+}
+
+// withdraw takes a token for one retry and reports whether one was left.
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// deposit records a successful request.
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.tokens = min(b.tokens+b.ratio, b.burst)
+	b.mu.Unlock()
+}
+
 // sleepWithContext sleeps for the given duration, returning early if context is cancelled.
 func sleepWithContext(ctx context.Context, d time.Duration) error {
 	select {
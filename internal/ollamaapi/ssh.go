@@ -0,0 +1,270 @@
+package ollamaapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultSSHTarget is where an ssh:// host without a path tunnels to.
+const defaultSSHTarget = "127.0.0.1:11434"
+
+// SSHConfig holds the credentials for ssh:// hosts. Empty fields use the
+// OpenSSH defaults: the agent at $SSH_AUTH_SOCK plus ~/.ssh/id_ed25519,
+// ~/.ssh/id_ecdsa and ~/.ssh/id_rsa for authentication, and
+// ~/.ssh/known_hosts for checking the server's host key.
+type SSHConfig struct {
+	IdentityFiles   []string
+	KnownHostsFiles []string
+}
+
+// WithSSHConfig sets the keys and known_hosts files used for ssh:// hosts.
+func WithSSHConfig(cfg SSHConfig) ClientOption {
+	return func(c *clientConfig) { c.ssh = cfg }
+}
+
+// sshTunnel dials the Ollama port through one SSH connection, which is opened
+// on first use and reopened after it drops.
+type sshTunnel struct {
+	addr    string // SSH server, host:port
+	user    string
+	target  string // address dialed from the SSH server
+	cfg     SSHConfig
+	dialer  *net.Dialer
+	timeout time.Duration
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// newSSHTunnel returns the tunnel for an ssh://user@server[:port]/target URL.
+func newSSHTunnel(u *url.URL, cfg SSHConfig, dialer *net.Dialer, timeout time.Duration) *sshTunnel {
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+	target := strings.Trim(u.Path, "/")
+	if target == "" {
+		target = defaultSSHTarget
+	}
+	return &sshTunnel{
+		addr:    net.JoinHostPort(u.Hostname(), port),
+		user:    u.User.Username(),
+		target:  target,
+		cfg:     cfg,
+		dialer:  dialer,
+		timeout: timeout,
+	}
+}
+
+// DialContext opens a connection to the tunnel target. The network and
+// address chosen by the HTTP transport are ignored.
+func (t *sshTunnel) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	client, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, "tcp", t.target)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: dial %s: %w", t.addr, t.target, err)
+	}
+	return conn, nil
+}
+
+// connect returns the SSH connection, opening it if needed.
+func (t *sshTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+	config, closeAgent, err := t.clientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", t.addr, err)
+	}
+	defer closeAgent()
+
+	conn, err := t.dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", t.addr, err)
+	}
+	// The handshake is bounded like the dial and aborted with ctx.
+	conn.SetDeadline(time.Now().Add(t.timeout))
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	c, chans, reqs, err := ssh.NewClientConn(conn, t.addr, config)
+	stop()
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("ssh %s: %w", t.addr, err)
+	}
+	conn.SetDeadline(time.Time{})
+	client := ssh.NewClient(c, chans, reqs)
+	t.client = client
+	go func() {
+		client.Wait()
+		t.mu.Lock()
+		if t.client == client {
+			t.client = nil
+		}
+		t.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// Close closes the SSH connection if it is open.
+func (t *sshTunnel) Close() error {
+	t.mu.Lock()
+	client := t.client
+	t.client = nil
+	t.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+	return client.Close()
+}
+
+// clientConfig loads the keys and known hosts. The returned function closes
+// the agent connection once the handshake is done.
+func (t *sshTunnel) clientConfig() (*ssh.ClientConfig, func(), error) {
+	name := t.user
+	if name == "" {
+		u, err := user.Current()
+		if err != nil {
+			return nil, nil, fmt.Errorf("no ssh user given: %w", err)
+		}
+		name = u.Username
+	}
+	hostKey, algos, err := knownHostsCallback(t.cfg.KnownHostsFiles, t.addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	signers, closeAgent := sshSigners(t.cfg.IdentityFiles)
+	if len(signers) == 0 {
+		closeAgent()
+		if len(t.cfg.IdentityFiles) > 0 {
+			return nil, nil, fmt.Errorf("no usable ssh key in %s (start ssh-agent or use an unencrypted key)", strings.Join(t.cfg.IdentityFiles, ", "))
+		}
+		return nil, nil, errors.New("no usable ssh key (start ssh-agent or add an unencrypted key such as ~/.ssh/id_ed25519)")
+	}
+	return &ssh.ClientConfig{
+		User:              name,
+		Auth:              []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback:   hostKey,
+		HostKeyAlgorithms: algos,
+	}, closeAgent, nil
+}
+
+// sshSigners returns the keys offered to the server: those held by the agent,
+// then the identity files. Passphrase-protected files are skipped; their keys
+// are expected to be in the agent.
+func sshSigners(files []string) ([]ssh.Signer, func()) {
+	var signers []ssh.Signer
+	closeAgent := func() {}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			closeAgent = func() { conn.Close() }
+			if s, err := agent.NewClient(conn).Signers(); err == nil {
+				signers = append(signers, s...)
+			}
+		}
+	}
+	if len(files) == 0 {
+		files = defaultSSHPaths("id_ed25519", "id_ecdsa", "id_rsa")
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if s, err := ssh.ParsePrivateKey(b); err == nil {
+			signers = append(signers, s)
+		}
+	}
+	return signers, closeAgent
+}
+
+// knownHostsCallback returns a host key check against the known_hosts files
+// and the key algorithms known for addr, so the server is asked for a key
+// type that can be verified.
+func knownHostsCallback(files []string, addr string) (ssh.HostKeyCallback, []string, error) {
+	if len(files) == 0 {
+		files = defaultSSHPaths("known_hosts")
+	}
+	var existing []string
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, nil, fmt.Errorf("no known_hosts file (%s); connect once with ssh to verify the host key", strings.Join(files, ", "))
+	}
+	db, err := knownhosts.New(existing...)
+	if err != nil {
+		return nil, nil, err
+	}
+	check := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := db(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return fmt.Errorf("host key for %s is not in known_hosts; connect once with ssh to verify and add it", hostname)
+			}
+			return fmt.Errorf("host key for %s does not match known_hosts", hostname)
+		}
+		return err
+	}
+	return check, knownKeyAlgorithms(db, addr), nil
+}
+
+// knownKeyAlgorithms looks up the key types known_hosts holds for addr by
+// checking a key that cannot match.
+func knownKeyAlgorithms(db ssh.HostKeyCallback, addr string) []string {
+	var keyErr *knownhosts.KeyError
+	if !errors.As(db(addr, &net.TCPAddr{}, probeKey{}), &keyErr) {
+		return nil
+	}
+	var algos []string
+	for _, k := range keyErr.Want {
+		if k.Key.Type() == ssh.KeyAlgoRSA {
+			algos = append(algos, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+		}
+		algos = append(algos, k.Key.Type())
+	}
+	return algos
+}
+
+// probeKey is a public key that matches no known_hosts entry.
+type probeKey struct{}
+
+func (probeKey) Type() string                        { return "probe" }
+func (probeKey) Marshal() []byte                     { return nil }
+func (probeKey) Verify([]byte, *ssh.Signature) error { return errors.New("probe key") }
+
+// defaultSSHPaths returns the names joined to ~/.ssh.
+func defaultSSHPaths(names ...string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, len(names))
+	for i, n := range names {
+		paths[i] = filepath.Join(home, ".ssh", n)
+	}
+	return paths
+}
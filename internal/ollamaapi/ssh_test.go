@@ -0,0 +1,156 @@
+package ollamaapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshServer starts an SSH server that accepts clientKey and forwards
+// direct-tcpip channels. It returns the server address and the host key.
+func sshServer(t *testing.T, clientKey ssh.PublicKey) (string, ssh.Signer) {
+	t.Helper()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, fmt.Errorf("unknown key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+	return ln.Addr().String(), hostKey
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		var req struct {
+			Host     string
+			Port     uint32
+			OrigHost string
+			OrigPort uint32
+		}
+		if nc.ChannelType() != "direct-tcpip" || ssh.Unmarshal(nc.ExtraData(), &req) != nil {
+			nc.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		target, err := net.Dial("tcp", net.JoinHostPort(req.Host, fmt.Sprint(req.Port)))
+		if err != nil {
+			nc.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		ch, chReqs, err := nc.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(chReqs)
+		go func() {
+			io.Copy(ch, target)
+			ch.Close()
+		}()
+		go func() {
+			io.Copy(target, ch)
+			target.Close()
+		}()
+	}
+}
+
+// sshClientFiles writes a client key and an empty known_hosts file.
+func sshClientFiles(t *testing.T) (SSHConfig, ssh.PublicKey) {
+	t.Helper()
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	knownFile := filepath.Join(dir, "known_hosts")
+	if err := os.WriteFile(knownFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	signer, _ := ssh.NewSignerFromKey(priv)
+	return SSHConfig{IdentityFiles: []string{keyFile}, KnownHostsFiles: []string{knownFile}}, signer.PublicKey()
+}
+
+func TestSSHTunnel(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"0.5.0"}`)
+	}))
+	defer s.Close()
+	target := strings.TrimPrefix(s.URL, "http://")
+
+	cfg, clientKey := sshClientFiles(t)
+	addr, hostKey := sshServer(t, clientKey)
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey.PublicKey())
+	if err := os.WriteFile(cfg.KnownHostsFiles[0], []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &url.URL{Scheme: "ssh", User: url.User("ollama"), Host: addr, Path: "/" + target}
+	c := NewClient(u, false, WithSSHConfig(cfg))
+	defer c.Close()
+	for i := 0; i < 2; i++ {
+		v, err := c.Version(context.Background())
+		if err != nil || v != "0.5.0" {
+			t.Fatalf("Version = %q, %v", v, err)
+		}
+	}
+}
+
+func TestSSHUnknownHostKey(t *testing.T) {
+	cfg, clientKey := sshClientFiles(t)
+	addr, _ := sshServer(t, clientKey)
+
+	u := &url.URL{Scheme: "ssh", User: url.User("ollama"), Host: addr}
+	c := NewClient(u, false, WithSSHConfig(cfg))
+	defer c.Close()
+	_, err := c.Version(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not in known_hosts") {
+		t.Fatalf("expected an unknown host key error, got %v", err)
+	}
+}
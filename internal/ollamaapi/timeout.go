@@ -0,0 +1,171 @@
+package ollamaapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Timeouts bound API requests; a zero value disables a timeout. The client
+// has no overall http.Client.Timeout because streamed responses legitimately
+// run for a long time, so these watch the stream instead.
+type Timeouts struct {
+	// Request bounds a whole request, including its streamed response.
+	Request time.Duration
+	// FirstToken bounds the wait for the first chunk of a streamed
+	// response: the first token of a generation or the first pull status.
+	FirstToken time.Duration
+	// StreamIdle bounds the silence between two chunks of a streamed
+	// response.
+	StreamIdle time.Duration
+}
+
+// WithTimeouts sets the timeouts of the client's requests.
+func WithTimeouts(t Timeouts) ClientOption {
+	return func(c *clientConfig) { c.timeouts = t }
+}
+
+type timeoutsKey struct{}
+
+// ContextWithTimeouts returns a context whose requests use t instead of the
+// client's timeouts.
+func ContextWithTimeouts(ctx context.Context, t Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, t)
+}
+
+// TimeoutKind identifies the timeout that expired by its setting name.
+type TimeoutKind string
+
+const (
+	TimeoutRequest    TimeoutKind = "request_timeout"
+	TimeoutFirstToken TimeoutKind = "first_token_timeout"
+	TimeoutStreamIdle TimeoutKind = "stream_idle_timeout"
+)
+
+// TimeoutError is returned when a request is canceled by one of its
+// Timeouts. It is not retried.
+type TimeoutError struct {
+	Kind     TimeoutKind
+	Timeout  time.Duration
+	Endpoint string
+}
+
+func (e *TimeoutError) Error() string {
+	var what string
+	switch e.Kind {
+	case TimeoutFirstToken:
+		what = fmt.Sprintf("no response within %s", e.Timeout)
+	case TimeoutStreamIdle:
+		what = fmt.Sprintf("stream stalled, nothing received for %s", e.Timeout)
+	default:
+		what = fmt.Sprintf("request timed out after %s", e.Timeout)
+	}
+	return fmt.Sprintf("ollama api %s: %s (%s)", e.Endpoint, what, e.Kind)
+}
+
+// watchdog cancels the context of one request when a timeout expires, with
+// the TimeoutError as the cause.
+type watchdog struct {
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	t        Timeouts
+	endpoint string
+
+	mu     sync.Mutex
+	timers []*time.Timer
+	first  *time.Timer
+}
+
+type watchedKey struct{}
+
+// watch starts the timeouts for a request to path. Streamed requests also
+// watch for the first chunk. Within an outer watch, such as one around all
+// attempts of a resumed pull, the request timeout is left to the outer one so
+// it bounds the whole request. The caller must call stop when done.
+func (c *Client) watch(ctx context.Context, path string, stream bool) (context.Context, *watchdog) {
+	t := c.timeouts
+	if v, ok := ctx.Value(timeoutsKey{}).(Timeouts); ok {
+		t = v
+	}
+	nested := ctx.Value(watchedKey{}) != nil
+	ctx, cancel := context.WithCancelCause(context.WithValue(ctx, watchedKey{}, true))
+	w := &watchdog{ctx: ctx, cancel: cancel, t: t, endpoint: path}
+	if t.Request > 0 && !nested {
+		w.after(TimeoutRequest, t.Request)
+	}
+	if stream && t.FirstToken > 0 {
+		w.first = w.after(TimeoutFirstToken, t.FirstToken)
+	}
+	return ctx, w
+}
+
+// after cancels the request with a TimeoutError of kind once d has passed.
+func (w *watchdog) after(kind TimeoutKind, d time.Duration) *time.Timer {
+	timer := time.AfterFunc(d, func() {
+		w.cancel(&TimeoutError{Kind: kind, Timeout: d, Endpoint: w.endpoint})
+	})
+	w.mu.Lock()
+	w.timers = append(w.timers, timer)
+	w.mu.Unlock()
+	return timer
+}
+
+// stop ends the watch and releases the request context.
+func (w *watchdog) stop() {
+	w.mu.Lock()
+	for _, t := range w.timers {
+		t.Stop()
+	}
+	w.mu.Unlock()
+	w.cancel(nil)
+}
+
+// err returns the TimeoutError that canceled the request in place of err,
+// which then only reports the cancellation.
+func (w *watchdog) err(err error) error {
+	var te *TimeoutError
+	if err != nil && errors.As(context.Cause(w.ctx), &te) {
+		return te
+	}
+	return err
+}
+
+// watchedBody is a streamed response body under a watchdog: the first chunk
+// stops the first-token timeout and each later read is bounded by the
+// stream-idle timeout. Closing it ends the watch.
+type watchedBody struct {
+	io.ReadCloser
+	w       *watchdog
+	started bool
+	idle    *time.Timer
+}
+
+func (b *watchedBody) Read(p []byte) (int, error) {
+	if b.started && b.w.t.StreamIdle > 0 {
+		if b.idle == nil {
+			b.idle = b.w.after(TimeoutStreamIdle, b.w.t.StreamIdle)
+		} else {
+			b.idle.Reset(b.w.t.StreamIdle)
+		}
+	}
+	n, err := b.ReadCloser.Read(p)
+	if b.idle != nil {
+		b.idle.Stop()
+	}
+	if n > 0 && !b.started {
+		b.started = true
+		if b.w.first != nil {
+			b.w.first.Stop()
+		}
+	}
+	return n, b.w.err(err)
+}
+
+func (b *watchedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.w.stop()
+	return err
+}
@@ -0,0 +1,109 @@
+package ollamaapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stallingServer sends the given chunks of a streamed response, then stops
+// sending until the client gives up.
+func stallingServer(t *testing.T, chunks ...string) *url.URL {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client hanging up once the body is read.
+		io.Copy(io.Discard, r.Body)
+		if len(chunks) > 0 {
+			for _, c := range chunks {
+				fmt.Fprintln(w, c)
+			}
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(s.Close)
+	u, _ := url.Parse(s.URL)
+	return u
+}
+
+func wantTimeout(t *testing.T, err error, kind TimeoutKind) {
+	t.Helper()
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Kind != kind {
+		t.Fatalf("expected a %s error, got %v", kind, err)
+	}
+	if !strings.Contains(err.Error(), string(kind)) {
+		t.Fatalf("error does not name the setting: %v", err)
+	}
+}
+
+func TestFirstTokenTimeout(t *testing.T) {
+	c := NewClient(stallingServer(t), false, WithTimeouts(Timeouts{FirstToken: 50 * time.Millisecond}))
+	var out strings.Builder
+	_, err := c.Generate(context.Background(), GenerateRequest{Model: "m", Prompt: "p"}, &out)
+	wantTimeout(t, err, TimeoutFirstToken)
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	u := stallingServer(t, `{"response":"partial"}`)
+	// The first-token timeout no longer applies once the stream has started.
+	c := NewClient(u, false, WithTimeouts(Timeouts{FirstToken: 20 * time.Millisecond, StreamIdle: 100 * time.Millisecond}))
+	var out strings.Builder
+	_, err := c.Generate(context.Background(), GenerateRequest{Model: "m", Prompt: "p"}, &out)
+	wantTimeout(t, err, TimeoutStreamIdle)
+	if out.String() != "partial" {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	c := NewClient(stallingServer(t), false, WithDefaultRetry(), WithTimeouts(Timeouts{Request: 50 * time.Millisecond}))
+	start := time.Now()
+	_, err := c.Version(context.Background())
+	wantTimeout(t, err, TimeoutRequest)
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("timed out request was retried (took %s)", d)
+	}
+}
+
+func TestContextWithTimeouts(t *testing.T) {
+	u := stallingServer(t, `{"status":"pulling manifest"}`)
+	c := NewClient(u, false, WithTimeouts(Timeouts{StreamIdle: time.Hour}))
+	ctx := ContextWithTimeouts(context.Background(), Timeouts{StreamIdle: 50 * time.Millisecond})
+	var out strings.Builder
+	err := c.Pull(ctx, "m", &out)
+	wantTimeout(t, err, TimeoutStreamIdle)
+}
+
+func TestRequestTimeoutAcrossResumes(t *testing.T) {
+	var mu sync.Mutex
+	completed := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		completed += 10
+		n := completed
+		mu.Unlock()
+		// Each attempt gets further, so the pull keeps being resumed.
+		fmt.Fprintf(w, `{"status":"pulling a","digest":"sha256:a","total":1000,"completed":%d}`+"\n", n)
+		time.Sleep(20 * time.Millisecond)
+		dropConnection(t, w)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	c := NewClient(u, false, fastRetry(3), WithTimeouts(Timeouts{Request: 200 * time.Millisecond}))
+	start := time.Now()
+	err := c.Pull(context.Background(), "m", io.Discard)
+	wantTimeout(t, err, TimeoutRequest)
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("request timeout fired after %s", d)
+	}
+}
@@ -0,0 +1,36 @@
+package ollamaapi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// WithTLSConfig sets the TLS configuration for https hosts (custom roots,
+// client certificates, server name).
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *clientConfig) {
+		if cfg != nil {
+			c.tlsConfig = cfg.Clone()
+		}
+	}
+}
+
+// ConnectionState requests /api/version and returns the TLS state of the
+// connection, or nil for plain-HTTP hosts. It is meant for diagnostics: any
+// HTTP status counts as a completed handshake. A multi-host client asks the
+// host requests currently go to.
+func (c *Client) ConnectionState(ctx context.Context) (*tls.ConnectionState, error) {
+	h := c.active()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.endpoint("/api/version"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := h.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	resp.Body.Close()
+	return resp.TLS, nil
+}
@@ -0,0 +1,482 @@
+package ollamaapi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceBodyLimit caps how much of each request and response body is kept in
+// a trace, so a long pull does not exhaust memory.
+const traceBodyLimit = 8 << 20
+
+// traceRedacted replaces the values of sensitive headers in a trace.
+const traceRedacted = "<redacted>"
+
+// Tracer records every request and response of the clients it is attached to
+// (see WithTracer) as a HAR 1.2 file. Entries are written to disk as soon as
+// their response body has been read, so a trace survives an interrupted run.
+//
+// Bodies are kept as text up to a limit; binary uploads and downloads only
+// have their size recorded. Authorization, cookies and the extra header names
+// passed to NewTracer are redacted.
+type Tracer struct {
+	path    string
+	version string
+	redact  map[string]bool
+
+	mu      sync.Mutex
+	entries []*harEntry
+	err     error
+}
+
+// NewTracer returns a Tracer writing to path. version is recorded as the
+// creator version; redactHeaders names headers whose values must not appear
+// in the file in addition to the built-in credential headers.
+func NewTracer(path, version string, redactHeaders ...string) *Tracer {
+	t := &Tracer{
+		path:    path,
+		version: version,
+		redact: map[string]bool{
+			"Authorization":       true,
+			"Proxy-Authorization": true,
+			"Cookie":              true,
+			"Set-Cookie":          true,
+		},
+	}
+	for _, h := range redactHeaders {
+		t.redact[http.CanonicalHeaderKey(h)] = true
+	}
+	return t
+}
+
+// WithTracer records the client's HTTP traffic with t.
+func WithTracer(t *Tracer) ClientOption {
+	return func(c *clientConfig) { c.tracer = t }
+}
+
+// Close writes the trace file, including requests that never completed, and
+// returns the first error met while writing it.
+func (t *Tracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.writeLocked()
+	return t.err
+}
+
+func (t *Tracer) add(e *harEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, e)
+}
+
+// update runs fn, which modifies an entry, under the tracer's lock.
+func (t *Tracer) update(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn()
+}
+
+// finish runs fn, which completes an entry, and rewrites the file.
+func (t *Tracer) finish(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn()
+	t.writeLocked()
+}
+
+func (t *Tracer) writeLocked() {
+	entries := make([]harEntry, 0, len(t.entries))
+	for _, e := range t.entries {
+		entries = append(entries, *e)
+	}
+	var doc harFile
+	doc.Log.Version = "1.2"
+	doc.Log.Creator = harCreator{Name: "ollama-remote", Version: t.version}
+	doc.Log.Entries = entries
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err == nil {
+		// Replace the file in one step so a reader never sees half a trace.
+		tmp := t.path + ".tmp"
+		if err = os.WriteFile(tmp, b, 0o600); err == nil {
+			err = os.Rename(tmp, t.path)
+		}
+	}
+	if err != nil && t.err == nil {
+		t.err = fmt.Errorf("write trace %s: %w", filepath.Base(t.path), err)
+	}
+}
+
+func (t *Tracer) headers(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for _, k := range sortedHeaderNames(h) {
+		for _, v := range h[k] {
+			if t.redact[http.CanonicalHeaderKey(k)] {
+				v = traceRedacted
+			}
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+// traceTransport records each round trip with its Tracer.
+type traceTransport struct {
+	base   http.RoundTripper
+	tracer *Tracer
+}
+
+func (t *traceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	tm := &traceTiming{start: time.Now()}
+	e := &harEntry{
+		StartedDateTime: tm.start.Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      r.Method,
+			URL:         r.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     t.tracer.headers(r.Header),
+			QueryString: queryString(r),
+			HeadersSize: -1,
+			BodySize:    r.ContentLength,
+		},
+		Cache: struct{}{},
+	}
+	t.tracer.add(e)
+
+	r = r.WithContext(httptrace.WithClientTrace(r.Context(), tm.clientTrace()))
+	var reqBody *captureBody
+	if r.Body != nil && r.Body != http.NoBody {
+		reqBody = &captureBody{ReadCloser: r.Body, keep: isTextual(r.Header.Get("Content-Type"))}
+		r.Body = reqBody
+	}
+
+	resp, err := t.base.RoundTrip(r)
+	tm.headers = time.Now()
+	if err != nil {
+		t.tracer.finish(func() {
+			e.Error = err.Error()
+			e.Response = harResponse{Cookies: []harNameValue{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: -1}
+			t.complete(e, tm, reqBody, r)
+		})
+		return nil, err
+	}
+	t.tracer.update(func() {
+		e.Response = harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))),
+			HTTPVersion: resp.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     t.tracer.headers(resp.Header),
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+		}
+		e.Request.HTTPVersion = resp.Proto
+	})
+	respBody := &captureBody{ReadCloser: resp.Body, keep: isTextual(resp.Header.Get("Content-Type"))}
+	respBody.onDone = func(rerr error) {
+		t.tracer.finish(func() {
+			if rerr != nil {
+				e.Error = rerr.Error()
+			}
+			e.Response.BodySize = respBody.size
+			e.Response.Content = respBody.content(resp.Header.Get("Content-Type"))
+			t.complete(e, tm, reqBody, r)
+		})
+	}
+	resp.Body = respBody
+	return resp, nil
+}
+
+// complete fills in the request body and timings once the exchange is over.
+func (t *traceTransport) complete(e *harEntry, tm *traceTiming, reqBody *captureBody, r *http.Request) {
+	if reqBody != nil {
+		e.Request.BodySize = reqBody.size
+		c := reqBody.content(r.Header.Get("Content-Type"))
+		e.Request.PostData = &harPostData{MimeType: c.MimeType, Text: c.Text, Comment: c.Comment}
+	}
+	e.ServerIPAddress = tm.remoteIP
+	e.Timings = tm.timings(time.Now())
+	e.Time = e.Timings.total()
+}
+
+// captureBody passes a body through while keeping a copy of textual content
+// (JSON, NDJSON) up to traceBodyLimit. onDone runs once, at EOF, on a read
+// error or on Close.
+type captureBody struct {
+	io.ReadCloser
+	keep      bool
+	buf       bytes.Buffer
+	size      int64
+	truncated bool
+	onDone    func(error)
+	once      sync.Once
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if b.keep && n > 0 {
+		if room := traceBodyLimit - b.buf.Len(); room >= n {
+			b.buf.Write(p[:n])
+		} else {
+			b.buf.Write(p[:room])
+			b.truncated = true
+		}
+	}
+	switch {
+	case err == io.EOF:
+		b.done(nil)
+	case err != nil:
+		b.done(err)
+	}
+	return n, err
+}
+
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done(nil)
+	return err
+}
+
+func (b *captureBody) done(err error) {
+	b.once.Do(func() {
+		if b.onDone != nil {
+			b.onDone(err)
+		}
+	})
+}
+
+func (b *captureBody) content(contentType string) harContent {
+	c := harContent{Size: b.size, MimeType: contentType}
+	switch {
+	case !b.keep:
+		if b.size > 0 {
+			c.Comment = "binary body not captured"
+		}
+	default:
+		c.Text = b.buf.String()
+		if b.truncated {
+			c.Comment = fmt.Sprintf("truncated to %d bytes", traceBodyLimit)
+		}
+	}
+	return c
+}
+
+// isTextual reports whether a body of this content type is worth keeping.
+// Requests without a type are the client's own JSON.
+func isTextual(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mt, "text/") || strings.Contains(mt, "json") || strings.HasSuffix(mt, "+xml")
+}
+
+func queryString(r *http.Request) []harNameValue {
+	out := []harNameValue{}
+	q := r.URL.Query()
+	for _, k := range sortedHeaderNames(http.Header(q)) {
+		for _, v := range q[k] {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func sortedHeaderNames(h http.Header) []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// traceTiming collects the phases of one request from httptrace callbacks.
+type traceTiming struct {
+	mu                                           sync.Mutex
+	start, headers                               time.Time
+	dnsStart, dnsDone, connStart, connDone       time.Time
+	tlsStart, tlsDone, gotConn, wrote, firstByte time.Time
+	remoteIP                                     string
+}
+
+func (tm *traceTiming) clientTrace() *httptrace.ClientTrace {
+	set := func(f *time.Time) {
+		tm.mu.Lock()
+		if f.IsZero() {
+			*f = time.Now()
+		}
+		tm.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&tm.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&tm.dnsDone) },
+		ConnectStart:      func(string, string) { set(&tm.connStart) },
+		ConnectDone:       func(string, string, error) { set(&tm.connDone) },
+		TLSHandshakeStart: func() { set(&tm.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&tm.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			set(&tm.gotConn)
+			tm.mu.Lock()
+			if addr := info.Conn.RemoteAddr(); addr != nil && addr.Network() == "tcp" {
+				tm.remoteIP = addr.String()
+			}
+			tm.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&tm.wrote) },
+		GotFirstResponseByte: func() { set(&tm.firstByte) },
+	}
+}
+
+// timings converts the collected timestamps into HAR phases in milliseconds;
+// phases that did not happen (a reused connection has no DNS or connect) are -1.
+func (tm *traceTiming) timings(end time.Time) harTimings {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	ms := func(from, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return -1
+		}
+		return float64(to.Sub(from).Microseconds()) / 1000
+	}
+	firstByte := tm.firstByte
+	if firstByte.IsZero() {
+		firstByte = tm.headers
+	}
+	connEnd := tm.connDone
+	if !tm.tlsDone.IsZero() {
+		connEnd = tm.tlsDone
+	}
+	t := harTimings{
+		DNS:     ms(tm.dnsStart, tm.dnsDone),
+		Connect: ms(tm.connStart, connEnd),
+		SSL:     ms(tm.tlsStart, tm.tlsDone),
+		Send:    ms(tm.gotConn, tm.wrote),
+		Wait:    ms(tm.wrote, firstByte),
+		Receive: ms(firstByte, end),
+	}
+	// Blocked is the time before the connection was ready that is not
+	// accounted for by DNS and connect.
+	if b := ms(tm.start, tm.gotConn); b >= 0 {
+		for _, p := range []float64{t.DNS, t.Connect} {
+			if p > 0 {
+				b -= p
+			}
+		}
+		t.Blocked = max(b, 0)
+	} else {
+		t.Blocked = -1
+	}
+	for _, p := range []*float64{&t.Send, &t.Wait, &t.Receive} {
+		if *p < 0 {
+			*p = 0
+		}
+	}
+	return t
+}
+
+// HAR 1.2 structures (http://www.softwareishard.com/blog/har-12-spec/).
+// Fields starting with an underscore are custom extensions.
+type harFile struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// total is the entry time: the sum of all phases that happened, except SSL,
+// which HAR counts as part of connect.
+func (t harTimings) total() float64 {
+	var sum float64
+	for _, p := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if p > 0 {
+			sum += p
+		}
+	}
+	return sum
+}
@@ -0,0 +1,126 @@
+package ollamaapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTracerRecordsHAR(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/generate":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			fmt.Fprintln(w, `{"response":"Hel","done":false}`)
+			w.(http.Flusher).Flush()
+			fmt.Fprintln(w, `{"response":"lo","done":true}`)
+		case "/api/blobs/sha256:" + strings.Repeat("a", 64):
+			io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"not found"}`)
+		}
+	}))
+	defer s.Close()
+
+	path := filepath.Join(t.TempDir(), "trace.har")
+	tracer := NewTracer(path, "test", "X-Tenant")
+	u, _ := url.Parse(s.URL)
+	h := http.Header{}
+	h.Set("Authorization", "Bearer sk-secret")
+	h.Set("X-Tenant", "research")
+	c := NewClient(u, false, WithHeaders(h), WithTracer(tracer))
+	ctx := context.Background()
+
+	var out strings.Builder
+	if _, err := c.Generate(ctx, GenerateRequest{Model: "m", Prompt: "hi", Stream: true}, &out); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if err := c.CreateBlob(ctx, "sha256:"+strings.Repeat("a", 64), strings.NewReader("GGUF-binary"), 11); err != nil {
+		t.Fatalf("CreateBlob: %v", err)
+	}
+	if _, err := c.Show(ctx, "missing"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read trace: %v", err)
+	}
+	if strings.Contains(string(raw), "sk-secret") || strings.Contains(string(raw), "research") {
+		t.Fatalf("credentials leaked into trace:\n%s", raw)
+	}
+	var har harFile
+	if err := json.Unmarshal(raw, &har); err != nil {
+		t.Fatalf("decode HAR: %v", err)
+	}
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 3 {
+		t.Fatalf("unexpected log: version=%s entries=%d", har.Log.Version, len(har.Log.Entries))
+	}
+
+	gen := har.Log.Entries[0]
+	if gen.Request.Method != http.MethodPost || !strings.HasSuffix(gen.Request.URL, "/api/generate") {
+		t.Fatalf("unexpected request: %+v", gen.Request)
+	}
+	if gen.Request.PostData == nil || !strings.Contains(gen.Request.PostData.Text, `"prompt":"hi"`) {
+		t.Fatalf("request body not captured: %+v", gen.Request.PostData)
+	}
+	if gen.Response.Status != 200 || !strings.Contains(gen.Response.Content.Text, `"response":"lo"`) {
+		t.Fatalf("streamed body not captured: %+v", gen.Response)
+	}
+	auth := ""
+	for _, hv := range gen.Request.Headers {
+		if hv.Name == "Authorization" {
+			auth = hv.Value
+		}
+	}
+	if auth != traceRedacted {
+		t.Fatalf("expected redacted Authorization, got %q", auth)
+	}
+	if gen.Time <= 0 || gen.Timings.Wait < 0 || gen.Timings.Receive < 0 {
+		t.Fatalf("unexpected timings: %v %+v", gen.Time, gen.Timings)
+	}
+
+	blob := har.Log.Entries[1]
+	if blob.Request.BodySize != 11 || blob.Request.PostData == nil || blob.Request.PostData.Text != "" {
+		t.Fatalf("binary upload should only record its size: %+v", blob.Request)
+	}
+	if show := har.Log.Entries[2]; show.Response.Status != http.StatusNotFound || !strings.Contains(show.Response.Content.Text, "not found") {
+		t.Fatalf("unexpected error entry: %+v", show.Response)
+	}
+}
+
+func TestTracerRecordsFailedRequest(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	u, _ := url.Parse(s.URL)
+	s.Close()
+
+	path := filepath.Join(t.TempDir(), "trace.har")
+	tracer := NewTracer(path, "test")
+	if _, err := NewClient(u, false, WithTracer(tracer)).Version(context.Background()); err == nil {
+		t.Fatalf("expected connection error")
+	}
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	var har harFile
+	if err := json.Unmarshal(raw, &har); err != nil || len(har.Log.Entries) != 1 {
+		t.Fatalf("decode HAR: %v (%s)", err, raw)
+	}
+	if e := har.Log.Entries[0]; e.Error == "" || e.Response.Status != 0 {
+		t.Fatalf("expected failed entry, got %+v", e)
+	}
+}
@@ -1,6 +1,9 @@
 package ollamaapi
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type VersionResponse struct {
 	Version string `json:"version"`
@@ -35,16 +38,115 @@ type ShowRequest struct {
 	Name string `json:"name"`
 }
 
+// ShowResponse is the response of /api/show.
+type ShowResponse struct {
+	License    string       `json:"license,omitempty"`
+	Modelfile  string       `json:"modelfile,omitempty"`
+	Parameters string       `json:"parameters,omitempty"`
+	Template   string       `json:"template,omitempty"`
+	System     string       `json:"system,omitempty"`
+	Details    ModelDetails `json:"details"`
+	// Messages are the MESSAGE instructions baked into the model.
+	Messages []ChatMessage `json:"messages,omitempty"`
+	// ModelInfo and ProjectorInfo hold GGUF metadata keyed like
+	// "general.architecture" or "llama.context_length".
+	ModelInfo     map[string]any `json:"model_info,omitempty"`
+	ProjectorInfo map[string]any `json:"projector_info,omitempty"`
+	Capabilities  []string       `json:"capabilities,omitempty"`
+	ModifiedAt    time.Time      `json:"modified_at"`
+}
+
+// ModelDetails describes a model's format and size class.
+type ModelDetails struct {
+	ParentModel       string   `json:"parent_model,omitempty"`
+	Format            string   `json:"format,omitempty"`
+	Family            string   `json:"family,omitempty"`
+	Families          []string `json:"families,omitempty"`
+	ParameterSize     string   `json:"parameter_size,omitempty"`
+	QuantizationLevel string   `json:"quantization_level,omitempty"`
+}
+
 type GenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model    string `json:"model"`
+	Prompt   string `json:"prompt"`
+	Suffix   string `json:"suffix,omitempty"`
+	System   string `json:"system,omitempty"`
+	Template string `json:"template,omitempty"`
+	Raw      bool   `json:"raw,omitempty"`
+	// Format is either the JSON string "json" or a JSON Schema object.
+	Format    json.RawMessage `json:"format,omitempty"`
+	Images    []string        `json:"images,omitempty"`
+	Think     *bool           `json:"think,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+	Stream    bool            `json:"stream"`
 }
 
 type GenerateChunk struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-	Error    string `json:"error"`
+	Model      string `json:"model,omitempty"`
+	Response   string `json:"response"`
+	Done       bool   `json:"done"`
+	DoneReason string `json:"done_reason,omitempty"`
+	Error      string `json:"error"`
+	Metrics
+}
+
+// Metrics are the timing statistics reported in the final streamed chunk.
+// Durations are sent by the server in nanoseconds.
+type Metrics struct {
+	TotalDuration      time.Duration `json:"total_duration,omitempty"`
+	LoadDuration       time.Duration `json:"load_duration,omitempty"`
+	PromptEvalCount    int           `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int           `json:"eval_count,omitempty"`
+	EvalDuration       time.Duration `json:"eval_duration,omitempty"`
+}
+
+// GenerateResult summarizes a completed /api/generate call.
+type GenerateResult struct {
+	Model      string
+	DoneReason string
+	Metrics
+	// FirstToken is the time from sending the request until the first
+	// response token arrived, measured by the client.
+	FirstToken time.Duration
+}
+
+// ChatMessage is a single message in a chat conversation.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest represents a request to the /api/chat endpoint.
+type ChatRequest struct {
+	Model     string         `json:"model"`
+	Messages  []ChatMessage  `json:"messages"`
+	Stream    bool           `json:"stream"`
+	KeepAlive string         `json:"keep_alive,omitempty"`
+	Options   map[string]any `json:"options,omitempty"`
+}
+
+// ChatChunk is a single streamed response object from /api/chat.
+type ChatChunk struct {
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error"`
+}
+
+// EmbedRequest represents a request to the /api/embed endpoint.
+type EmbedRequest struct {
+	Model     string         `json:"model"`
+	Input     []string       `json:"input"`
+	Truncate  *bool          `json:"truncate,omitempty"`
+	Options   map[string]any `json:"options,omitempty"`
+	KeepAlive string         `json:"keep_alive,omitempty"`
+}
+
+// EmbedResponse is the response from the /api/embed endpoint.
+type EmbedResponse struct {
+	Model      string      `json:"model"`
+	Embeddings [][]float64 `json:"embeddings"`
 }
 
 type PullRequest struct {
@@ -52,6 +154,13 @@ type PullRequest struct {
 	Stream bool   `json:"stream"`
 }
 
+// PushRequest represents a request to push a model to a registry.
+type PushRequest struct {
+	Model    string `json:"model"`
+	Insecure bool   `json:"insecure,omitempty"`
+	Stream   bool   `json:"stream"`
+}
+
 type PullChunk struct {
 	Status    string `json:"status"`
 	Digest    string `json:"digest"`
@@ -60,6 +169,21 @@ type PullChunk struct {
 	Error     string `json:"error"`
 }
 
+// CreateRequest represents a request to create a model via /api/create.
+type CreateRequest struct {
+	Model      string            `json:"model"`
+	From       string            `json:"from,omitempty"`
+	Files      map[string]string `json:"files,omitempty"`
+	Adapters   map[string]string `json:"adapters,omitempty"`
+	Template   string            `json:"template,omitempty"`
+	License    []string          `json:"license,omitempty"`
+	System     string            `json:"system,omitempty"`
+	Parameters map[string]any    `json:"parameters,omitempty"`
+	Messages   []ChatMessage     `json:"messages,omitempty"`
+	Quantize   string            `json:"quantize,omitempty"`
+	Stream     bool              `json:"stream"`
+}
+
 // DeleteRequest represents a request to delete a model.
 type DeleteRequest struct {
 	Name string `json:"name"`
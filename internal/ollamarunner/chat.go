@@ -0,0 +1,244 @@
+package ollamarunner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/modelfile"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+// multilineDelimiter starts and ends a multi-line message in the chat REPL.
+const multilineDelimiter = `"""`
+
+// chatSession holds the state of an interactive native chat.
+type chatSession struct {
+	model     string
+	system    string
+	keepAlive string
+	options   map[string]any
+	messages  []ollamaapi.ChatMessage
+}
+
+func newChatSession(model string) *chatSession {
+	return &chatSession{model: model, options: map[string]any{}}
+}
+
+// request builds the /api/chat request for the current history.
+func (s *chatSession) request() ollamaapi.ChatRequest {
+	msgs := make([]ollamaapi.ChatMessage, 0, len(s.messages)+1)
+	if strings.TrimSpace(s.system) != "" {
+		msgs = append(msgs, ollamaapi.ChatMessage{Role: "system", Content: s.system})
+	}
+	msgs = append(msgs, s.messages...)
+	req := ollamaapi.ChatRequest{Model: s.model, Messages: msgs, Stream: true, KeepAlive: s.keepAlive}
+	if len(s.options) > 0 {
+		req.Options = s.options
+	}
+	return req
+}
+
+// runChat runs an interactive chat session reading user input from opts.Stdin.
+func runChat(ctx context.Context, client *ollamaapi.Client, s *chatSession, opts Options, tr *i18n.Bundle) (int, error) {
+	in := bufio.NewReader(opts.Stdin)
+	out := opts.Stdout
+	errOut := opts.Stderr
+	if errOut == nil {
+		errOut = out
+	}
+
+	fmt.Fprintln(out, tr.Sprintf("native.chat.welcome", "model", s.model))
+	for {
+		input, err := readChatInput(in, out)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				fmt.Fprintln(out)
+				return 0, nil
+			}
+			return 1, err
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		if strings.HasPrefix(input, "/") {
+			if quit := s.handleCommand(input, out, tr); quit {
+				return 0, nil
+			}
+			continue
+		}
+
+		s.messages = append(s.messages, ollamaapi.ChatMessage{Role: "user", Content: input})
+		reply, err := client.Chat(ctx, s.request(), out)
+		if err != nil {
+			// Drop the unanswered message so the history stays consistent.
+			s.messages = s.messages[:len(s.messages)-1]
+			if ctx.Err() != nil {
+				return 1, err
+			}
+			fmt.Fprintln(out)
+			fmt.Fprintln(errOut, err.Error())
+			continue
+		}
+		s.messages = append(s.messages, reply)
+		fmt.Fprint(out, "\n\n")
+	}
+}
+
+// readChatInput reads one message, joining lines between """ delimiters.
+func readChatInput(r *bufio.Reader, w io.Writer) (string, error) {
+	fmt.Fprint(w, ">>> ")
+	line, err := readLine(r)
+	if err != nil {
+		return "", err
+	}
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, multilineDelimiter) {
+		return line, nil
+	}
+
+	rest := strings.TrimPrefix(trimmed, multilineDelimiter)
+	if strings.HasSuffix(rest, multilineDelimiter) {
+		return strings.TrimSuffix(rest, multilineDelimiter), nil
+	}
+	var b strings.Builder
+	b.WriteString(rest)
+	for {
+		fmt.Fprint(w, "... ")
+		next, err := readLine(r)
+		if err != nil {
+			return "", err
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if t := strings.TrimRight(next, " \t"); strings.HasSuffix(t, multilineDelimiter) {
+			b.WriteString(strings.TrimSuffix(t, multilineDelimiter))
+			return b.String(), nil
+		}
+		b.WriteString(next)
+	}
+}
+
+// readLine reads a single line without its line terminator.
+// A final line without a trailing newline is returned before io.EOF.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// handleCommand executes a slash command and reports whether the session should end.
+func (s *chatSession) handleCommand(input string, w io.Writer, tr *i18n.Bundle) bool {
+	fields := strings.Fields(input)
+	switch fields[0] {
+	case "/bye", "/exit":
+		return true
+	case "/?", "/help":
+		for _, k := range []string{
+			"native.chat.help.set_system",
+			"native.chat.help.set_parameter",
+			"native.chat.help.show",
+			"native.chat.help.clear",
+			"native.chat.help.bye",
+			"native.chat.help.help",
+			"native.chat.help.multiline",
+		} {
+			fmt.Fprintln(w, tr.Sprintf(k))
+		}
+	case "/clear":
+		s.messages = nil
+		fmt.Fprintln(w, tr.Sprintf("native.chat.cleared"))
+	case "/set":
+		if len(fields) < 2 {
+			fmt.Fprintln(w, tr.Sprintf("native.chat.usage_set"))
+			break
+		}
+		switch fields[1] {
+		case "system":
+			// Keep the original spacing of the system message.
+			rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), "/set"))
+			s.system = strings.TrimSpace(strings.TrimPrefix(rest, "system"))
+			fmt.Fprintln(w, tr.Sprintf("native.chat.system_set"))
+		case "parameter":
+			if len(fields) < 4 {
+				fmt.Fprintln(w, tr.Sprintf("native.chat.usage_set"))
+				break
+			}
+			name := fields[2]
+			value := strings.Join(fields[3:], " ")
+			modelfile.SetParameter(s.options, name, value)
+			fmt.Fprintln(w, tr.Sprintf("native.chat.parameter_set", "name", name, "value", value))
+		default:
+			fmt.Fprintln(w, tr.Sprintf("native.chat.usage_set"))
+		}
+	case "/show":
+		sub := ""
+		if len(fields) > 1 {
+			sub = fields[1]
+		}
+		s.show(sub, w, tr)
+	default:
+		fmt.Fprintln(w, tr.Sprintf("native.chat.unknown_command", "cmd", fields[0]))
+	}
+	return false
+}
+
+func (s *chatSession) show(sub string, w io.Writer, tr *i18n.Bundle) {
+	none := tr.Sprintf("native.chat.value.none")
+	system := s.system
+	if strings.TrimSpace(system) == "" {
+		system = none
+	}
+	switch sub {
+	case "":
+		fmt.Fprintln(w, tr.Sprintf("native.chat.show_model", "value", s.model))
+		fmt.Fprintln(w, tr.Sprintf("native.chat.show_system", "value", system))
+		s.showParameters(w, tr)
+		fmt.Fprintln(w, tr.Sprintf("native.chat.show_messages", "value", strconv.Itoa(len(s.messages))))
+	case "system":
+		fmt.Fprintln(w, tr.Sprintf("native.chat.show_system", "value", system))
+	case "parameters":
+		s.showParameters(w, tr)
+	default:
+		fmt.Fprintln(w, tr.Sprintf("native.chat.usage_show"))
+	}
+}
+
+func (s *chatSession) showParameters(w io.Writer, tr *i18n.Bundle) {
+	if len(s.options) == 0 {
+		fmt.Fprintln(w, tr.Sprintf("native.chat.show_parameters", "value", tr.Sprintf("native.chat.value.none")))
+		return
+	}
+	fmt.Fprintln(w, tr.Sprintf("native.chat.show_parameters", "value", ""))
+	names := make([]string, 0, len(s.options))
+	for k := range s.options {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintf(w, "  %s %v\n", k, s.options[k])
+	}
+}
+
+// isTerminal reports whether v (a reader or writer) is an interactive
+// character device.
+func isTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	st, err := f.Stat()
+	return err == nil && (st.Mode()&os.ModeCharDevice) != 0
+}
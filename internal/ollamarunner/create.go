@@ -0,0 +1,125 @@
+package ollamarunner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/modelfile"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+var createFlags = flagSpec{
+	"f":        true,
+	"file":     true,
+	"q":        true,
+	"quantize": true,
+}
+
+// runCreate implements: create NAME [-f Modelfile] [-q QUANT]
+func runCreate(ctx context.Context, client *ollamaapi.Client, args []string, opts Options, tr *i18n.Bundle) (int, error) {
+	flags, rest, err := parseCmdArgs(args, createFlags)
+	if err != nil {
+		return 2, translateFlagError(tr, err)
+	}
+	if len(rest) < 1 || strings.TrimSpace(rest[0]) == "" {
+		return 2, errors.New(tr.Sprintf("error.native.usage_create"))
+	}
+	if !opts.Unsafe {
+		return 2, errors.New(tr.Sprintf("error.native.create_requires_unsafe"))
+	}
+	name := strings.TrimSpace(rest[0])
+	// The blobs must be on the server that creates the model.
+	ctx = client.PinHost(ctx)
+
+	path := firstNonEmpty(flags.String("file"), flags.String("f"), "Modelfile")
+	mf, err := modelfile.ParseFile(path)
+	if err != nil {
+		return 1, err
+	}
+
+	// Uploads and create statuses share one progress display.
+	progress, closeProgress := progressWriter(opts)
+	defer closeProgress()
+	opts.Stdout = progress
+
+	// Local weights and adapters are invisible to the remote server, so they
+	// are uploaded as blobs and referenced by digest.
+	req := mf.CreateRequest(name)
+	dir := filepath.Dir(path)
+	if p, local := modelfile.LocalPath(mf.From, dir); local {
+		files, err := uploadModelFiles(ctx, client, p, opts)
+		if err != nil {
+			return 1, err
+		}
+		req.From = ""
+		req.Files = files
+	}
+	for _, a := range mf.Adapters {
+		p, local := modelfile.LocalPath(a, dir)
+		if !local {
+			return 1, errors.New(tr.Sprintf("error.native.create_adapter_not_found", "path", a))
+		}
+		files, err := uploadModelFiles(ctx, client, p, opts)
+		if err != nil {
+			return 1, err
+		}
+		if req.Adapters == nil {
+			req.Adapters = map[string]string{}
+		}
+		for k, v := range files {
+			req.Adapters[k] = v
+		}
+	}
+
+	req.Quantize = strings.TrimSpace(firstNonEmpty(flags.String("quantize"), flags.String("q")))
+	if err := client.Create(ctx, req, opts.Stdout); err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+// uploadModelFiles uploads path, or every regular file directly inside it when
+// it is a directory (e.g. safetensors weights), and maps file names to digests.
+func uploadModelFiles(ctx context.Context, client *ollamaapi.Client, path string, opts Options) (map[string]string, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{path}
+	if st.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = paths[:0]
+		for _, e := range entries {
+			if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			paths = append(paths, filepath.Join(path, e.Name()))
+		}
+	}
+
+	files := make(map[string]string, len(paths))
+	for _, p := range paths {
+		digest, err := client.UploadFile(ctx, p, opts.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Base(p)] = digest
+	}
+	return files, nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package ollamarunner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+const (
+	// defaultEmbedBatchSize is the number of inputs sent per /api/embed request.
+	defaultEmbedBatchSize = 32
+	// maxEmbedLineSize bounds a single stdin line read by embed.
+	maxEmbedLineSize = 4 * 1024 * 1024
+)
+
+var embedFlags = flagSpec{
+	"format":      true,
+	"batch":       true,
+	"no-truncate": false,
+}
+
+// runEmbed implements: embed MODEL [--format json|ndjson] [--batch N] [--no-truncate] [TEXT...]
+//
+// Without TEXT arguments, each non-empty stdin line is embedded separately.
+func runEmbed(ctx context.Context, client *ollamaapi.Client, args []string, opts Options, tr *i18n.Bundle) (int, error) {
+	flags, rest, err := parseCmdArgs(args, embedFlags)
+	if err != nil {
+		return 2, translateFlagError(tr, err)
+	}
+	if len(rest) < 1 || strings.TrimSpace(rest[0]) == "" {
+		return 2, errors.New(tr.Sprintf("error.native.usage_embed"))
+	}
+
+	format := strings.ToLower(strings.TrimSpace(flags.String("format")))
+	if format == "" {
+		format = "ndjson"
+	}
+	if format != "json" && format != "ndjson" {
+		return 2, errors.New(tr.Sprintf("error.native.embed_invalid_format", "format", format))
+	}
+	batch := defaultEmbedBatchSize
+	if flags.Has("batch") {
+		n, err := strconv.Atoi(strings.TrimSpace(flags.String("batch")))
+		if err != nil || n <= 0 {
+			return 2, errors.New(tr.Sprintf("error.native.invalid_number", "flag", "--batch", "value", flags.String("batch")))
+		}
+		batch = n
+	}
+
+	req := ollamaapi.EmbedRequest{Model: strings.TrimSpace(rest[0])}
+	if flags.Bool("no-truncate") {
+		truncate := false
+		req.Truncate = &truncate
+	}
+	out := &embedWriter{w: opts.Stdout, format: format, model: req.Model}
+
+	flush := func(inputs []string) error {
+		for len(inputs) > 0 {
+			n := batch
+			if n > len(inputs) {
+				n = len(inputs)
+			}
+			req.Input = inputs[:n]
+			vecs, err := client.Embed(ctx, req)
+			if err != nil {
+				return err
+			}
+			if err := out.write(req.Input, vecs); err != nil {
+				return err
+			}
+			inputs = inputs[n:]
+		}
+		return nil
+	}
+
+	if texts := rest[1:]; len(texts) > 0 {
+		if err := flush(texts); err != nil {
+			return 1, err
+		}
+		return 0, out.close()
+	}
+
+	if opts.Stdin == nil || isTerminal(opts.Stdin) {
+		return 2, errors.New(tr.Sprintf("error.native.usage_embed"))
+	}
+	sc := bufio.NewScanner(opts.Stdin)
+	sc.Buffer(make([]byte, 0, 64*1024), maxEmbedLineSize)
+	pending := make([]string, 0, batch)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		pending = append(pending, line)
+		if len(pending) == batch {
+			if err := flush(pending); err != nil {
+				return 1, err
+			}
+			pending = pending[:0]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 1, err
+	}
+	if err := flush(pending); err != nil {
+		return 1, err
+	}
+	if out.count == 0 {
+		return 2, errors.New(tr.Sprintf("error.native.usage_embed"))
+	}
+	return 0, out.close()
+}
+
+// embedWriter prints vectors as NDJSON lines as they arrive, or collects them
+// into a single /api/embed-shaped JSON document.
+type embedWriter struct {
+	w      io.Writer
+	format string
+	model  string
+	count  int
+	all    [][]float64
+}
+
+type embedLine struct {
+	Input     string    `json:"input"`
+	Embedding []float64 `json:"embedding"`
+}
+
+func (e *embedWriter) write(inputs []string, vecs [][]float64) error {
+	e.count += len(vecs)
+	if e.format == "json" {
+		e.all = append(e.all, vecs...)
+		return nil
+	}
+	enc := json.NewEncoder(e.w)
+	for i, v := range vecs {
+		if err := enc.Encode(embedLine{Input: inputs[i], Embedding: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *embedWriter) close() error {
+	if e.format != "json" {
+		return nil
+	}
+	return json.NewEncoder(e.w).Encode(ollamaapi.EmbedResponse{Model: e.model, Embeddings: e.all})
+}
@@ -0,0 +1,164 @@
+package ollamarunner
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli_ollama_server/internal/config"
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+// flagSpec lists the flags a native subcommand accepts, keyed by name
+// without leading dashes. The value reports whether the flag takes a value.
+type flagSpec map[string]bool
+
+// cmdFlags holds the parsed flags of a native subcommand.
+type cmdFlags map[string][]string
+
+// Has reports whether the flag was given.
+func (f cmdFlags) Has(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+// String returns the last value given for the flag, or "".
+func (f cmdFlags) String(name string) string {
+	v := f[name]
+	if len(v) == 0 {
+		return ""
+	}
+	return v[len(v)-1]
+}
+
+// All returns every value given for a repeatable flag.
+func (f cmdFlags) All(name string) []string {
+	return f[name]
+}
+
+// Bool returns the value of a boolean flag.
+func (f cmdFlags) Bool(name string) bool {
+	if !f.Has(name) {
+		return false
+	}
+	return parseBoolFlag(f.String(name))
+}
+
+// flagError describes a malformed subcommand flag.
+type flagError struct {
+	Kind string
+	Flag string
+}
+
+func (e *flagError) Error() string {
+	return e.Kind + ": " + e.Flag
+}
+
+// parseCmdArgs splits subcommand args into flags and positional args.
+//
+// Flags may appear before and after the first positional arg (the model), as
+// -name or --name, with values given as the next argument or after "=".
+// Parsing stops at the second positional arg, so a prompt or text may contain
+// words starting with "-", like upstream `ollama run`. Negative numbers,
+// quoted args with a space before any "=" ("-v is a flag") and everything
+// after "--" are positional.
+func parseCmdArgs(args []string, spec flagSpec) (cmdFlags, []string, error) {
+	flags := cmdFlags{}
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		}
+		if len(a) < 2 || !strings.HasPrefix(a, "-") || isNumber(a) || isText(a) {
+			rest = append(rest, a)
+			if len(rest) == 2 {
+				rest = append(rest, args[i+1:]...)
+				break
+			}
+			continue
+		}
+		name := strings.TrimLeft(a, "-")
+		val, hasVal := "", false
+		if k, v, ok := strings.Cut(name, "="); ok {
+			name, val, hasVal = k, v, true
+		}
+		takesValue, known := spec[name]
+		if !known {
+			return nil, nil, &flagError{Kind: "unknown_flag", Flag: a}
+		}
+		switch {
+		case hasVal:
+		case takesValue:
+			if i+1 >= len(args) {
+				return nil, nil, &flagError{Kind: "missing_value", Flag: a}
+			}
+			val = args[i+1]
+			i++
+		default:
+			val = "true"
+		}
+		flags[name] = append(flags[name], val)
+	}
+	return flags, rest, nil
+}
+
+// translateFlagError converts a flagError into a localized error.
+func translateFlagError(tr *i18n.Bundle, err error) error {
+	var fe *flagError
+	if errors.As(err, &fe) {
+		return errors.New(tr.Sprintf("error.arg."+fe.Kind, "flag", fe.Flag))
+	}
+	return err
+}
+
+// isNumber reports whether a looks like a (negative) number rather than a flag.
+func isNumber(a string) bool {
+	_, err := strconv.ParseFloat(a, 64)
+	return err == nil
+}
+
+// isText reports whether a is a quoted phrase such as "-v is a flag" rather
+// than a flag: flag names never contain spaces.
+func isText(a string) bool {
+	name, _, _ := strings.Cut(a, "=")
+	return strings.ContainsAny(name, " \t\n")
+}
+
+func parseBoolFlag(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == "1" || strings.EqualFold(v, "true") || strings.EqualFold(v, "yes") || strings.EqualFold(v, "y")
+}
+
+// withTimeoutFlags applies --request-timeout, --first-token-timeout and
+// --stream-idle-timeout on top of the configured timeouts.
+func withTimeoutFlags(ctx context.Context, flags cmdFlags, opts Options, tr *i18n.Bundle) (context.Context, error) {
+	t := opts.Timeouts
+	set := false
+	for _, f := range []struct {
+		name string
+		out  *time.Duration
+	}{
+		{"request-timeout", &t.Request},
+		{"first-token-timeout", &t.FirstToken},
+		{"stream-idle-timeout", &t.StreamIdle},
+	} {
+		if !flags.Has(f.name) {
+			continue
+		}
+		d, err := config.ParseTimeout(flags.String(f.name))
+		if err != nil {
+			return nil, errors.New(tr.Sprintf("error.native.invalid_timeout", "flag", "--"+f.name, "value", flags.String(f.name)))
+		}
+		*f.out = d
+		set = true
+	}
+	if !set {
+		return ctx, nil
+	}
+	return ollamaapi.ContextWithTimeouts(ctx, t), nil
+}
@@ -0,0 +1,68 @@
+package ollamarunner
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"cli_ollama_server/internal/config"
+)
+
+// needsRelay reports whether the upstream CLI needs the relay to reach the
+// host: it can neither send custom headers, use custom TLS settings, dial a
+// Unix socket nor open an SSH tunnel, so the relay serves as its local
+// forwarded port. A trace also goes through the relay so it records the
+// child's requests.
+func needsRelay(opts Options) bool {
+	if len(opts.Headers) > 0 || opts.Trace != nil {
+		return true
+	}
+	u, err := config.ParseHostURL(opts.Host)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "unix" || u.Scheme == "ssh" || (opts.TLS != nil && u.Scheme == "https")
+}
+
+// startRelay serves the native client's Relay on a loopback port so the
+// upstream CLI reaches the server with the configured headers and TLS settings. It returns the
+// URL to use as OLLAMA_HOST and a function that stops the relay.
+func startRelay(opts Options) (string, func(), error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return "", nil, err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		client.Close()
+		return "", nil, err
+	}
+	relay := client.Relay()
+	addr := ln.Addr().String()
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the child addresses the relay by its literal address; anything
+		// else (such as a DNS-rebound browser request) must not get credentials.
+		if r.Host != addr {
+			http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+			return
+		}
+		relay.ServeHTTP(w, r)
+	})}
+	go srv.Serve(ln)
+	return "http://" + addr, func() {
+		srv.Close()
+		client.Close()
+	}, nil
+}
+
+// setEnv returns env with key set to value, replacing any existing entry.
+func setEnv(env []string, key, value string) []string {
+	out := make([]string, 0, len(env)+1)
+	for _, kv := range env {
+		if k, _, ok := strings.Cut(kv, "="); ok && k == key {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return append(out, key+"="+value)
+}
@@ -0,0 +1,266 @@
+package ollamarunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/jsonschema"
+	"cli_ollama_server/internal/modelfile"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+var runFlags = flagSpec{
+	"system":        true,
+	"temperature":   true,
+	"num-ctx":       true,
+	"seed":          true,
+	"option":        true,
+	"keepalive":     true,
+	"format":        true,
+	"format-schema": true,
+	"retries":       true,
+	"image":         true,
+	"verbose":       false,
+
+	"request-timeout":     true,
+	"first-token-timeout": true,
+	"stream-idle-timeout": true,
+}
+
+// runGenerate implements: run MODEL [flags] [--] [PROMPT...]
+//
+// With a prompt (argument or piped stdin) it performs a single /api/generate
+// call; on a terminal without a prompt it starts an interactive chat.
+// With --format or --format-schema the response is buffered, validated and
+// only printed once it is valid JSON matching the schema.
+func runGenerate(ctx context.Context, client *ollamaapi.Client, args []string, opts Options, tr *i18n.Bundle) (int, error) {
+	flags, rest, err := parseCmdArgs(args, runFlags)
+	if err != nil {
+		return 2, translateFlagError(tr, err)
+	}
+	if len(rest) < 1 || strings.TrimSpace(rest[0]) == "" {
+		return 2, errors.New(tr.Sprintf("error.native.usage_run"))
+	}
+	model := strings.TrimSpace(rest[0])
+
+	options, err := generateOptions(flags, tr)
+	if err != nil {
+		return 2, err
+	}
+	keepAlive, err := normalizeKeepAlive(flags.String("keepalive"))
+	if err != nil {
+		return 2, errors.New(tr.Sprintf("error.native.invalid_duration", "flag", "--keepalive", "value", flags.String("keepalive")))
+	}
+	system := flags.String("system")
+	schema, code, err := runFormat(flags, opts, tr)
+	if err != nil {
+		return code, err
+	}
+	retries := 0
+	if flags.Has("retries") {
+		v := flags.String("retries")
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return 2, errors.New(tr.Sprintf("error.native.invalid_number", "flag", "--retries", "value", v))
+		}
+		if schema == nil {
+			return 2, errors.New(tr.Sprintf("error.native.retries_requires_format"))
+		}
+		retries = n
+	}
+	ctx, err = withTimeoutFlags(ctx, flags, opts, tr)
+	if err != nil {
+		return 2, err
+	}
+
+	var images []string
+	for _, path := range flags.All("image") {
+		img, err := ollamaapi.ReadImageFile(path)
+		if err != nil {
+			return 2, errors.New(tr.Sprintf("error.native.image_invalid", "error", err.Error()))
+		}
+		images = append(images, img)
+	}
+
+	prompt := strings.TrimSpace(strings.Join(rest[1:], " "))
+	if prompt == "" {
+		if stdin, rerr := readStdinIfPiped(opts.Stdin); rerr != nil {
+			return 1, rerr
+		} else if strings.TrimSpace(stdin) != "" {
+			prompt = stdin
+		}
+	}
+	if prompt == "" {
+		if isTerminal(opts.Stdin) && schema == nil && len(images) == 0 {
+			s := newChatSession(model)
+			s.system = system
+			s.keepAlive = keepAlive
+			for k, v := range options {
+				s.options[k] = v
+			}
+			return runChat(ctx, client, s, opts, tr)
+		}
+		return 2, errors.New(tr.Sprintf("error.native.run_requires_prompt"))
+	}
+
+	req := ollamaapi.GenerateRequest{
+		Model:     model,
+		Prompt:    prompt,
+		System:    system,
+		KeepAlive: keepAlive,
+		Images:    images,
+		Stream:    true,
+	}
+	if len(options) > 0 {
+		req.Options = options
+	}
+	verbose := flags.Bool("verbose")
+	if schema == nil {
+		res, err := client.Generate(ctx, req, opts.Stdout)
+		if err != nil {
+			return 1, err
+		}
+		if verbose {
+			printMetrics(opts, res)
+		}
+		return 0, nil
+	}
+	return generateStructured(ctx, client, req, schema, retries, verbose, opts, tr)
+}
+
+// runFormat resolves --format and --format-schema into a compiled schema.
+// A nil schema means the output is unconstrained.
+func runFormat(flags cmdFlags, opts Options, tr *i18n.Bundle) (*jsonschema.Schema, int, error) {
+	if path := strings.TrimSpace(flags.String("format-schema")); path != "" {
+		if f := strings.TrimSpace(flags.String("format")); f != "" && !strings.EqualFold(f, "json") {
+			return nil, 2, errors.New(tr.Sprintf("error.native.run_invalid_format", "format", f))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, 1, errors.New(tr.Sprintf("error.native.schema_read", "path", path, "error", err.Error()))
+		}
+		schema, err := jsonschema.Compile(data)
+		if err != nil {
+			return nil, 2, errors.New(tr.Sprintf("error.native.schema_invalid", "path", path, "error", err.Error()))
+		}
+		return schema, 0, nil
+	}
+	if !flags.Has("format") {
+		return nil, 0, nil
+	}
+	if f := strings.TrimSpace(flags.String("format")); !strings.EqualFold(f, "json") {
+		return nil, 2, errors.New(tr.Sprintf("error.native.run_invalid_format", "format", f))
+	}
+	// "true" accepts any JSON value; Validate still rejects malformed JSON.
+	schema, _ := jsonschema.Compile([]byte("true"))
+	return schema, 0, nil
+}
+
+// generateStructured asks for JSON output and validates the complete response,
+// re-sending the request up to retries times when validation fails.
+func generateStructured(ctx context.Context, client *ollamaapi.Client, req ollamaapi.GenerateRequest, schema *jsonschema.Schema, retries int, verbose bool, opts Options, tr *i18n.Bundle) (int, error) {
+	req.Format = json.RawMessage(`"json"`)
+	if raw := schema.Raw(); string(raw) != "true" {
+		req.Format = raw
+	}
+	errOut := opts.Stderr
+	if errOut == nil {
+		errOut = io.Discard
+	}
+
+	var verr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			fmt.Fprintln(errOut, tr.Sprintf("native.run.retrying", "error", verr.Error(), "attempt", strconv.Itoa(attempt), "max", strconv.Itoa(retries)))
+		}
+		var buf bytes.Buffer
+		res, err := client.Generate(ctx, req, &buf)
+		if err != nil {
+			return 1, err
+		}
+		if verbose {
+			printMetrics(opts, res)
+		}
+		out := bytes.TrimSpace(buf.Bytes())
+		if verr = schema.Validate(out); verr == nil {
+			if _, err := fmt.Fprintf(opts.Stdout, "%s\n", out); err != nil {
+				return 1, err
+			}
+			return 0, nil
+		}
+	}
+	return 1, errors.New(tr.Sprintf("error.native.output_invalid", "attempts", strconv.Itoa(retries+1), "error", verr.Error()))
+}
+
+// printMetrics writes --verbose statistics to stderr, after the response so the
+// two streams stay readable when they share a terminal.
+func printMetrics(opts Options, res ollamaapi.GenerateResult) {
+	if opts.Stderr == nil {
+		return
+	}
+	fmt.Fprint(opts.Stderr, "\n"+ollamaapi.FormatMetrics(res))
+}
+
+// generateOptions builds the model options map from --option k=v pairs and
+// the dedicated flags, which take precedence.
+func generateOptions(flags cmdFlags, tr *i18n.Bundle) (map[string]any, error) {
+	options := map[string]any{}
+	for _, kv := range flags.All("option") {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, errors.New(tr.Sprintf("error.native.invalid_option", "value", kv))
+		}
+		modelfile.SetParameter(options, k, v)
+	}
+	if flags.Has("temperature") {
+		v := flags.String("temperature")
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || f < 0 {
+			return nil, errors.New(tr.Sprintf("error.native.invalid_number", "flag", "--temperature", "value", v))
+		}
+		options["temperature"] = f
+	}
+	if flags.Has("num-ctx") {
+		v := flags.String("num-ctx")
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n <= 0 {
+			return nil, errors.New(tr.Sprintf("error.native.invalid_number", "flag", "--num-ctx", "value", v))
+		}
+		options["num_ctx"] = n
+	}
+	if flags.Has("seed") {
+		v := flags.String("seed")
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, errors.New(tr.Sprintf("error.native.invalid_number", "flag", "--seed", "value", v))
+		}
+		options["seed"] = n
+	}
+	return options, nil
+}
+
+// normalizeKeepAlive validates a keep-alive value. Bare integers are seconds,
+// matching the upstream CLI; negative values keep the model loaded indefinitely.
+func normalizeKeepAlive(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "", nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return strconv.FormatInt(n, 10) + "s", nil
+	}
+	if _, err := time.ParseDuration(v); err != nil {
+		return "", err
+	}
+	return v, nil
+}
@@ -3,12 +3,14 @@ package ollamarunner
 import (
 	"bufio"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"cli_ollama_server/internal/config"
 	"cli_ollama_server/internal/execollama"
@@ -22,6 +24,31 @@ type Options struct {
 	OllamaExe   string
 	NoProxyAuto bool
 	Unsafe      bool
+	// Hosts lists all servers when there are several; Host is then the
+	// first. Native commands fail over between them, wrapper mode hands the
+	// upstream CLI a healthy one.
+	Hosts []config.HostEntry
+	// Balance is "failover" or "round-robin" (see ollamaapi.Balance).
+	Balance string
+	// Headers are added to every API request (api_key and [headers]).
+	Headers http.Header
+	// TLS configures https hosts (ca_file, client certificates, server name).
+	TLS *tls.Config
+	// Proxy replaces the proxy from the environment when set; hosts
+	// matching NoProxy are reached directly.
+	Proxy   *url.URL
+	NoProxy []string
+	// SSH sets the key and known_hosts files for ssh:// hosts.
+	SSH ollamaapi.SSHConfig
+	// Timeouts bound native requests; run and pull override them with
+	// their --*-timeout flags.
+	Timeouts ollamaapi.Timeouts
+	// Backend is the server API ("ollama" or "openai"). The upstream CLI
+	// only speaks Ollama's API, so other backends always run natively.
+	Backend string
+	// Trace records the HTTP traffic of native commands and, through the
+	// relay, of the upstream CLI (--trace).
+	Trace *ollamaapi.Tracer
 
 	Env        []string
 	Args       []string
@@ -37,8 +64,18 @@ func Run(ctx context.Context, opts Options) (int, error) {
 		mode = "auto"
 	}
 
+	openAI := opts.Backend == string(ollamaapi.BackendOpenAI)
+	if mode == "wrapper" && openAI {
+		tr := opts.Translator
+		if tr == nil {
+			tr = i18n.New("en")
+		}
+		return 2, errors.New(tr.Sprintf("error.backend_requires_native", "backend", opts.Backend))
+	}
 	if mode == "auto" {
-		if _, err := execollama.ResolveExecutable(opts.OllamaExe); err == nil {
+		if openAI || NativeOnly(opts.Args) {
+			mode = "native"
+		} else if _, err := execollama.ResolveExecutable(opts.OllamaExe); err == nil {
 			mode = "wrapper"
 		} else {
 			mode = "native"
@@ -54,10 +91,35 @@ func Run(ctx context.Context, opts Options) (int, error) {
 		if ctx == nil {
 			ctx = context.Background()
 		}
+		env := opts.Env
+		if len(opts.Hosts) > 1 {
+			u, err := selectHost(ctx, opts)
+			if err != nil {
+				tr := opts.Translator
+				if tr == nil {
+					tr = i18n.New("en")
+				}
+				// The upstream CLI never ran, so nothing else reports this.
+				if opts.Stderr != nil {
+					fmt.Fprintln(opts.Stderr, tr.Sprintf("error.no_healthy_host", "error", err.Error()))
+				}
+				return 1, err
+			}
+			opts.Host, opts.Hosts = u.String(), nil
+			env = setEnv(env, "OLLAMA_HOST", opts.Host)
+		}
+		if needsRelay(opts) {
+			relayHost, stop, err := startRelay(opts)
+			if err != nil {
+				return 1, err
+			}
+			defer stop()
+			env = setEnv(env, "OLLAMA_HOST", relayHost)
+		}
 		return execollama.Run(execollama.RunOptions{
 			Context:   ctx,
 			Args:      opts.Args,
-			Env:       opts.Env,
+			Env:       env,
 			OllamaExe: exe,
 			Stdout:    opts.Stdout,
 			Stderr:    opts.Stderr,
@@ -70,6 +132,45 @@ func Run(ctx context.Context, opts Options) (int, error) {
 	}
 }
 
+// hostProbeTimeout bounds the health check that picks a host for the
+// upstream CLI.
+const hostProbeTimeout = 5 * time.Second
+
+// nativeOnlyCommands have no upstream CLI equivalent and always run natively
+// in auto mode.
+var nativeOnlyCommands = map[string]bool{
+	"embed": true,
+	"load":  true,
+}
+
+// backendCommands are the native commands that work with backends other than
+// Ollama's own API.
+var backendCommands = map[string]bool{
+	"list":  true,
+	"run":   true,
+	"embed": true,
+}
+
+// NativeOnly reports whether args name a command that only native mode implements.
+// The upstream `stop` exists but has no --all.
+func NativeOnly(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "stop" {
+		flags, _, err := parseCmdArgs(args[1:], stopFlags)
+		return err == nil && flags.Bool("all")
+	}
+	return nativeOnlyCommands[args[0]]
+}
+
+// pullFlags are the flags of native pull.
+var pullFlags = flagSpec{
+	"request-timeout":     true,
+	"first-token-timeout": true,
+	"stream-idle-timeout": true,
+}
+
 func runNative(ctx context.Context, opts Options) (int, error) {
 	if len(opts.Args) == 0 {
 		return 0, nil
@@ -82,13 +183,16 @@ func runNative(ctx context.Context, opts Options) (int, error) {
 		ctx = context.Background()
 	}
 
-	baseURL, err := config.ParseHostURL(opts.Host)
+	client, err := newClient(opts)
 	if err != nil {
 		return 2, err
 	}
-	client := ollamaapi.NewClient(baseURL, opts.NoProxyAuto)
+	defer client.Close()
 
 	cmd := opts.Args[0]
+	if client.Backend() != ollamaapi.BackendOllama && !backendCommands[cmd] {
+		return 2, errors.New(tr.Sprintf("error.native.backend_unsupported", "cmd", cmd, "backend", opts.Backend))
+	}
 	switch cmd {
 	case "--version":
 		v, err := client.Version(ctx)
@@ -112,52 +216,56 @@ func runNative(ctx context.Context, opts Options) (int, error) {
 		fmt.Fprint(opts.Stdout, ollamaapi.FormatPS(procs))
 		return 0, nil
 	case "show":
-		if len(opts.Args) < 2 {
-			return 2, errors.New(tr.Sprintf("error.native.usage_show"))
-		}
-		resp, err := client.Show(ctx, strings.TrimSpace(opts.Args[1]))
+		return runShow(ctx, client, opts.Args[1:], opts, tr)
+	case "pull":
+		flags, rest, err := parseCmdArgs(opts.Args[1:], pullFlags)
 		if err != nil {
-			return 1, err
+			return 2, translateFlagError(tr, err)
 		}
-		b, _ := json.MarshalIndent(resp, "", "  ")
-		fmt.Fprintln(opts.Stdout, string(b))
-		return 0, nil
-	case "pull":
-		if len(opts.Args) < 2 {
+		if len(rest) < 1 {
 			return 2, errors.New(tr.Sprintf("error.native.usage_pull"))
 		}
 		if !opts.Unsafe {
 			return 2, errors.New(tr.Sprintf("error.native.pull_requires_unsafe"))
 		}
-		if err := client.Pull(ctx, strings.TrimSpace(opts.Args[1]), opts.Stdout); err != nil {
+		ctx, err = withTimeoutFlags(ctx, flags, opts, tr)
+		if err != nil {
+			return 2, err
+		}
+		progress, closeProgress := progressWriter(opts)
+		defer closeProgress()
+		if err := client.Pull(ctx, strings.TrimSpace(rest[0]), progress); err != nil {
 			return 1, err
 		}
 		return 0, nil
-	case "run":
-		if len(opts.Args) < 2 {
-			return 2, errors.New(tr.Sprintf("error.native.usage_run"))
-		}
-		model := strings.TrimSpace(opts.Args[1])
-		rest := opts.Args[2:]
-		if len(rest) > 0 && rest[0] == "--" {
-			rest = rest[1:]
-		}
-		prompt := strings.TrimSpace(strings.Join(rest, " "))
-		if prompt == "" {
-			if stdin, rerr := readStdinIfPiped(opts.Stdin); rerr != nil {
-				return 1, rerr
-			} else if strings.TrimSpace(stdin) != "" {
-				prompt = stdin
-			}
+	case "push":
+		flags, rest, err := parseCmdArgs(opts.Args[1:], flagSpec{"insecure": false})
+		if err != nil {
+			return 2, translateFlagError(tr, err)
 		}
-		if prompt == "" {
-			return 2, errors.New(tr.Sprintf("error.native.run_requires_prompt"))
+		if len(rest) < 1 {
+			return 2, errors.New(tr.Sprintf("error.native.usage_push"))
+		}
+		if !opts.Unsafe {
+			return 2, errors.New(tr.Sprintf("error.native.push_requires_unsafe"))
 		}
-		req := ollamaapi.GenerateRequest{Model: model, Prompt: prompt, Stream: true}
-		if err := client.Generate(ctx, req, opts.Stdout); err != nil {
+		req := ollamaapi.PushRequest{Model: strings.TrimSpace(rest[0]), Insecure: flags.Bool("insecure")}
+		progress, closeProgress := progressWriter(opts)
+		defer closeProgress()
+		if err := client.Push(ctx, req, progress); err != nil {
 			return 1, err
 		}
 		return 0, nil
+	case "run":
+		return runGenerate(ctx, client, opts.Args[1:], opts, tr)
+	case "create":
+		return runCreate(ctx, client, opts.Args[1:], opts, tr)
+	case "embed":
+		return runEmbed(ctx, client, opts.Args[1:], opts, tr)
+	case "stop":
+		return runStop(ctx, client, opts.Args[1:], opts, tr)
+	case "load":
+		return runLoad(ctx, client, opts.Args[1:], opts, tr)
 	case "rm", "delete":
 		if len(opts.Args) < 2 {
 			return 2, errors.New(tr.Sprintf("error.native.usage_delete"))
@@ -187,17 +295,67 @@ func runNative(ctx context.Context, opts Options) (int, error) {
 	}
 }
 
+// newClient builds the API client for opts.Host (or opts.Hosts) with retries,
+// the configured request headers, TLS and SSH settings, backend and tracer.
+func newClient(opts Options) (*ollamaapi.Client, error) {
+	baseURL, err := config.ParseHostURL(opts.Host)
+	if err != nil {
+		return nil, err
+	}
+	copts := []ollamaapi.ClientOption{ollamaapi.WithDefaultRetry()}
+	if len(opts.Hosts) > 1 {
+		hosts := make([]ollamaapi.Host, 0, len(opts.Hosts))
+		for _, h := range opts.Hosts {
+			u, err := config.ParseHostURL(h.URL)
+			if err != nil {
+				return nil, err
+			}
+			hosts = append(hosts, ollamaapi.Host{URL: u, Weight: h.Weight})
+		}
+		copts = append(copts, ollamaapi.WithHosts(hosts...), ollamaapi.WithBalance(ollamaapi.Balance(opts.Balance)))
+	}
+	if len(opts.Headers) > 0 {
+		copts = append(copts, ollamaapi.WithHeaders(opts.Headers))
+	}
+	if opts.TLS != nil {
+		copts = append(copts, ollamaapi.WithTLSConfig(opts.TLS))
+	}
+	if opts.Proxy != nil || len(opts.NoProxy) > 0 {
+		copts = append(copts, ollamaapi.WithProxy(opts.Proxy, opts.NoProxy))
+	}
+	if len(opts.SSH.IdentityFiles) > 0 || len(opts.SSH.KnownHostsFiles) > 0 {
+		copts = append(copts, ollamaapi.WithSSHConfig(opts.SSH))
+	}
+	if opts.Timeouts != (ollamaapi.Timeouts{}) {
+		copts = append(copts, ollamaapi.WithTimeouts(opts.Timeouts))
+	}
+	if opts.Backend != "" {
+		copts = append(copts, ollamaapi.WithBackend(ollamaapi.Backend(opts.Backend)))
+	}
+	if opts.Trace != nil {
+		copts = append(copts, ollamaapi.WithTracer(opts.Trace))
+	}
+	return ollamaapi.NewClient(baseURL, opts.NoProxyAuto, copts...), nil
+}
+
+// selectHost probes opts.Hosts and returns the one the upstream CLI should use.
+func selectHost(ctx context.Context, opts Options) (*url.URL, error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(ctx, hostProbeTimeout)
+	defer cancel()
+	return client.SelectHost(ctx)
+}
+
 func readStdinIfPiped(r io.Reader) (string, error) {
 	if r == nil {
 		return "", nil
 	}
-	f, ok := r.(*os.File)
-	if ok {
-		if st, err := f.Stat(); err == nil {
-			if (st.Mode() & os.ModeCharDevice) != 0 {
-				return "", nil
-			}
-		}
+	if isTerminal(r) {
+		return "", nil
 	}
 	br := bufio.NewReader(r)
 	b, err := io.ReadAll(br)
@@ -206,3 +364,13 @@ func readStdinIfPiped(r io.Reader) (string, error) {
 	}
 	return string(b), nil
 }
+
+// progressWriter wraps opts.Stdout in a progress renderer for pull, push and
+// create. Writers that aggregate progress themselves are used as-is.
+func progressWriter(opts Options) (io.Writer, func()) {
+	if _, ok := opts.Stdout.(ollamaapi.ProgressReporter); ok {
+		return opts.Stdout, func() {}
+	}
+	p := ollamaapi.NewProgress(opts.Stdout, isTerminal(opts.Stdout))
+	return p, func() { p.Close() }
+}
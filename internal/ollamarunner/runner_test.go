@@ -2,14 +2,25 @@ package ollamarunner
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"cli_ollama_server/internal/config"
 	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
 )
 
 func TestNativeListAndRun(t *testing.T) {
@@ -56,6 +67,54 @@ func TestNativeListAndRun(t *testing.T) {
 	}
 }
 
+func TestOpenAIBackend(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			fmt.Fprint(w, `{"data":[{"id":"mistral-7b"}]}`)
+		case "/v1/chat/completions":
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hi!\"}}]}\n\ndata: [DONE]\n\n")
+		case "/v1/embeddings":
+			fmt.Fprint(w, `{"data":[{"index":0,"embedding":[0.5]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	run := func(mode string, args ...string) (int, string, error) {
+		var out strings.Builder
+		code, err := Run(context.Background(), Options{
+			Mode:       mode,
+			Host:       s.URL,
+			Backend:    "openai",
+			Args:       args,
+			Stdout:     &out,
+			Stderr:     &out,
+			Stdin:      strings.NewReader(""),
+			Translator: i18n.New("en"),
+		})
+		return code, out.String(), err
+	}
+
+	// Auto mode must not hand the command to an installed upstream CLI.
+	if code, out, err := run("auto", "list"); err != nil || code != 0 || !strings.Contains(out, "mistral-7b") {
+		t.Fatalf("list: code=%d err=%v out=%q", code, err, out)
+	}
+	if code, out, err := run("native", "run", "mistral-7b", "hello"); err != nil || code != 0 || out != "hi!" {
+		t.Fatalf("run: code=%d err=%v out=%q", code, err, out)
+	}
+	if code, out, err := run("native", "embed", "mistral-7b", "hello"); err != nil || code != 0 || !strings.Contains(out, "0.5") {
+		t.Fatalf("embed: code=%d err=%v out=%q", code, err, out)
+	}
+	if code, _, err := run("native", "ps"); code != 2 || err == nil || !strings.Contains(err.Error(), "openai backend") {
+		t.Fatalf("ps: code=%d err=%v", code, err)
+	}
+	if code, _, err := run("wrapper", "list"); code != 2 || err == nil || !strings.Contains(err.Error(), "native mode") {
+		t.Fatalf("wrapper: code=%d err=%v", code, err)
+	}
+}
+
 func TestNativePullRequiresUnsafe(t *testing.T) {
 	s := newFakeOllamaServer(t)
 	defer s.Close()
@@ -78,10 +137,645 @@ func TestNativePullRequiresUnsafe(t *testing.T) {
 	}
 }
 
+func TestNativeShow(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	run := func(args ...string) (string, int, error) {
+		var out strings.Builder
+		code, err := Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       s.URL,
+			Args:       append([]string{"show"}, args...),
+			Stdout:     &out,
+			Stderr:     &out,
+			Translator: i18n.New("en"),
+		})
+		return out.String(), code, err
+	}
+
+	out, code, err := run("llama3:8b")
+	if code != 0 || err != nil {
+		t.Fatalf("show: code=%d err=%v", code, err)
+	}
+	for _, want := range []string{"  Model\n", "architecture    llama", "parameters      8.0B", "quantization    Q4_0", "num_ctx    8192"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in summary:\n%s", want, out)
+		}
+	}
+
+	tests := map[string]string{
+		"--modelfile":  "FROM llama3:8b\n",
+		"--parameters": "num_ctx 8192\n",
+		"--template":   "{{ .Prompt }}\n",
+		"--system":     "No system is set for this model.\n",
+	}
+	for flag, want := range tests {
+		out, code, err := run("llama3:8b", flag)
+		if code != 0 || err != nil || out != want {
+			t.Errorf("show %s: code=%d err=%v out=%q, want %q", flag, code, err, out, want)
+		}
+	}
+
+	out, code, err = run("llama3:8b", "--json")
+	var decoded ollamaapi.ShowResponse
+	if code != 0 || err != nil || json.Unmarshal([]byte(out), &decoded) != nil || decoded.Details.QuantizationLevel != "Q4_0" {
+		t.Errorf("show --json: code=%d err=%v out=%q", code, err, out)
+	}
+	// Fields ShowResponse doesn't know are passed through.
+	if !strings.Contains(out, `"name": "token_embd.weight"`) {
+		t.Errorf("show --json dropped unknown fields:\n%s", out)
+	}
+
+	if _, code, err := run("llama3:8b", "--modelfile", "--license"); code != 2 || err == nil || !strings.Contains(err.Error(), "--modelfile, --license") {
+		t.Errorf("expected conflict error, got code=%d err=%v", code, err)
+	}
+	if _, code, _ := run(); code != 2 {
+		t.Errorf("expected usage error without model, got code=%d", code)
+	}
+}
+
+func TestNativeRunGenerationOptions(t *testing.T) {
+	var got ollamaapi.GenerateRequest
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{\"response\":\"ok\",\"done\":true}\n")
+	}))
+	defer s.Close()
+
+	var out strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode: "native",
+		Host: s.URL,
+		Args: []string{"run", "llama3:8b",
+			"--system", "be brief",
+			"--option", "top_k=20",
+			"--option", "temperature=0.9",
+			"--temperature", "0",
+			"--num-ctx", "8192",
+			"--seed=42",
+			"--keepalive", "300",
+			"what is", "-1", "+ 2",
+		},
+		Stdout:     &out,
+		Stderr:     &out,
+		Stdin:      strings.NewReader(""),
+		Translator: i18n.New("en"),
+	})
+	if err != nil || code != 0 {
+		t.Fatalf("run: code=%d err=%v out=%q", code, err, out.String())
+	}
+	if got.System != "be brief" || got.KeepAlive != "300s" || got.Prompt != "what is -1 + 2" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	want := map[string]any{"top_k": float64(20), "temperature": float64(0), "num_ctx": float64(8192), "seed": float64(42)}
+	for k, v := range want {
+		if got.Options[k] != v {
+			t.Errorf("option %s = %#v, want %#v", k, got.Options[k], v)
+		}
+	}
+}
+
+func TestNativeRunFormatSchemaRetries(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "schema.json")
+	schema := `{"type":"object","properties":{"n":{"type":"integer"}},"required":["n"]}`
+	if err := os.WriteFile(schemaPath, []byte(schema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replies := []string{`{\"n\":`, `{\"n\":\"two\"}`, `{\"n\":2}`}
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaapi.GenerateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if string(req.Format) != schema {
+			t.Errorf("format = %s", req.Format)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, "{\"response\":\"%s\",\"done\":true}\n", replies[calls])
+		calls++
+	}))
+	defer s.Close()
+
+	run := func(retries string) (stdout, stderr string, code int, err error) {
+		calls = 0
+		var outBuf, errBuf strings.Builder
+		code, err = Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       s.URL,
+			Args:       []string{"run", "m", "--format-schema", schemaPath, "--retries", retries, "count"},
+			Stdout:     &outBuf,
+			Stderr:     &errBuf,
+			Translator: i18n.New("en"),
+		})
+		return outBuf.String(), errBuf.String(), code, err
+	}
+
+	stdout, stderr, code, err := run("2")
+	if code != 0 || err != nil {
+		t.Fatalf("run: code=%d err=%v stderr=%q", code, err, stderr)
+	}
+	if stdout != `{"n":2}`+"\n" || calls != 3 {
+		t.Errorf("stdout=%q calls=%d", stdout, calls)
+	}
+	if strings.Count(stderr, "retrying") != 2 {
+		t.Errorf("expected two retry notices, got %q", stderr)
+	}
+
+	stdout, _, code, err = run("1")
+	if code != 1 || err == nil || !strings.Contains(err.Error(), "/n: expected integer") {
+		t.Errorf("expected validation failure, got code=%d err=%v", code, err)
+	}
+	if stdout != "" {
+		t.Errorf("invalid output must not be printed, got %q", stdout)
+	}
+}
+
+func TestNativeRunFormatJSON(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaapi.GenerateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if string(req.Format) != `"json"` {
+			t.Errorf("format = %s", req.Format)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{\"response\":\" [1, 2]\",\"done\":true}\n")
+	}))
+	defer s.Close()
+
+	var out strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Args:       []string{"run", "m", "--format", "json", "list"},
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: i18n.New("en"),
+	})
+	if code != 0 || err != nil || out.String() != "[1, 2]\n" {
+		t.Errorf("code=%d err=%v out=%q", code, err, out.String())
+	}
+}
+
+func TestNativeRunImages(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 16)...)
+	imgPath := filepath.Join(t.TempDir(), "cat.png")
+	if err := os.WriteFile(imgPath, png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got ollamaapi.GenerateRequest
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{\"response\":\"a cat\",\"done\":true}\n")
+	}))
+	defer s.Close()
+
+	var out strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Args:       []string{"run", "llava", "--image", imgPath, "describe this"},
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: i18n.New("en"),
+	})
+	if code != 0 || err != nil {
+		t.Fatalf("run: code=%d err=%v out=%q", code, err, out.String())
+	}
+	if len(got.Images) != 1 || got.Images[0] != base64.StdEncoding.EncodeToString(png) {
+		t.Errorf("images = %v", got.Images)
+	}
+
+	code, err = Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Args:       []string{"run", "llava", "--image", filepath.Join(t.TempDir(), "missing.png"), "hi"},
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: i18n.New("en"),
+	})
+	if code != 2 || err == nil || !strings.Contains(err.Error(), "Cannot attach image") {
+		t.Errorf("expected attach error, got code=%d err=%v", code, err)
+	}
+}
+
+func TestNativeRunVerbose(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{\"response\":\"hi\",\"done\":false}\n")
+		fmt.Fprint(w, "{\"done\":true,\"eval_count\":10,\"eval_duration\":500000000,\"load_duration\":1000000}\n")
+	}))
+	defer s.Close()
+
+	var stdout, stderr strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Args:       []string{"run", "m", "--verbose", "hello"},
+		Stdout:     &stdout,
+		Stderr:     &stderr,
+		Translator: i18n.New("en"),
+	})
+	if code != 0 || err != nil {
+		t.Fatalf("run: code=%d err=%v", code, err)
+	}
+	if stdout.String() != "hi" {
+		t.Errorf("stats must not go to stdout, got %q", stdout.String())
+	}
+	for _, want := range []string{"eval rate:            20.00 tokens/s", "load duration:        1ms", "time to first token:"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("expected %q in stderr:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestNativeRunInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"run", "m", "--temperature", "hot", "hi"},
+		{"run", "m", "--num-ctx", "0", "hi"},
+		{"run", "m", "--option", "novalue", "hi"},
+		{"run", "m", "--keepalive", "soon", "hi"},
+		{"run", "m", "--bogus", "hi"},
+		{"run", "m", "--format", "yaml", "hi"},
+		{"run", "m", "--format", "json", "--retries", "-1", "hi"},
+		{"run", "m", "--retries", "2", "hi"},
+		{"run", "m", "--first-token-timeout", "soon", "hi"},
+	} {
+		var out strings.Builder
+		code, err := Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       "http://127.0.0.1:1",
+			Args:       args,
+			Stdout:     &out,
+			Stderr:     &out,
+			Translator: i18n.New("en"),
+		})
+		if code != 2 || err == nil {
+			t.Errorf("%v: expected usage error, got code=%d err=%v", args, code, err)
+		}
+	}
+}
+
+func TestNativeTimeoutFlags(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer s.Close()
+
+	run := func(args ...string) (int, error) {
+		var out strings.Builder
+		return Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       s.URL,
+			Unsafe:     true,
+			Timeouts:   ollamaapi.Timeouts{FirstToken: time.Hour},
+			Args:       args,
+			Stdout:     &out,
+			Stderr:     &out,
+			Translator: i18n.New("en"),
+		})
+	}
+	code, err := run("run", "m", "--first-token-timeout", "50ms", "hi")
+	var te *ollamaapi.TimeoutError
+	if code != 1 || !errors.As(err, &te) || te.Kind != ollamaapi.TimeoutFirstToken {
+		t.Fatalf("run: code=%d err=%v", code, err)
+	}
+	code, err = run("pull", "m", "--request-timeout=0.05")
+	if code != 1 || !errors.As(err, &te) || te.Kind != ollamaapi.TimeoutRequest {
+		t.Fatalf("pull: code=%d err=%v", code, err)
+	}
+	if code, err := run("pull", "m", "--stream-idle-timeout", "-1s"); code != 2 || err == nil || !strings.Contains(err.Error(), "--stream-idle-timeout") {
+		t.Fatalf("pull with invalid timeout: code=%d err=%v", code, err)
+	}
+}
+
+func TestNativeChatREPL(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	client := ollamaapi.NewClient(u, false)
+
+	input := strings.Join([]string{
+		"/set system be brief",
+		"/set parameter temperature 0.2",
+		`"""first line`,
+		`second line"""`,
+		"/show",
+		"/clear",
+		"/show",
+		"/bye",
+	}, "\n")
+
+	var out strings.Builder
+	code, err := runChat(context.Background(), client, newChatSession("llama3:8b"), Options{
+		Stdin:  strings.NewReader(input),
+		Stdout: &out,
+		Stderr: &out,
+	}, i18n.New("en"))
+	if err != nil || code != 0 {
+		t.Fatalf("chat: code=%d err=%v out=%q", code, err, out.String())
+	}
+	got := out.String()
+	for _, want := range []string{"hi!", "System: be brief", "temperature 0.2", "Messages: 2", "Messages: 0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output, got %q", want, got)
+		}
+	}
+}
+
+func TestNativeEmbed(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	tr := i18n.New("en")
+
+	{
+		var out strings.Builder
+		code, err := Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       s.URL,
+			Args:       []string{"embed", "--batch", "2", "nomic-embed-text"},
+			Stdout:     &out,
+			Stderr:     &out,
+			Stdin:      strings.NewReader("one\n\ntwo\nthree\n"),
+			Translator: tr,
+		})
+		if err != nil || code != 0 {
+			t.Fatalf("embed: code=%d err=%v out=%q", code, err, out.String())
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 ndjson lines, got %q", out.String())
+		}
+		if lines[2] != `{"input":"three","embedding":[5]}` {
+			t.Fatalf("unexpected line: %q", lines[2])
+		}
+	}
+
+	{
+		var out strings.Builder
+		code, err := Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       s.URL,
+			Args:       []string{"embed", "nomic-embed-text", "--format=json", "a", "bb"},
+			Stdout:     &out,
+			Stderr:     &out,
+			Translator: tr,
+		})
+		if err != nil || code != 0 {
+			t.Fatalf("embed json: code=%d err=%v out=%q", code, err, out.String())
+		}
+		if strings.TrimSpace(out.String()) != `{"model":"nomic-embed-text","embeddings":[[1],[2]]}` {
+			t.Fatalf("unexpected json output: %q", out.String())
+		}
+	}
+}
+
+func TestNativeCreate(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Modelfile")
+	if err := os.WriteFile(path, []byte("FROM llama3:8b\nSYSTEM be brief\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := i18n.New("en")
+	args := []string{"create", "mine", "-f", path}
+
+	var out strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Args:       args,
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: tr,
+	})
+	if code != 2 || err == nil {
+		t.Fatalf("expected create to require unsafe, got code=%d err=%v", code, err)
+	}
+
+	out.Reset()
+	code, err = Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Unsafe:     true,
+		Args:       args,
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: tr,
+	})
+	if err != nil || code != 0 {
+		t.Fatalf("create: code=%d err=%v out=%q", code, err, out.String())
+	}
+	if !strings.Contains(out.String(), "success") {
+		t.Fatalf("expected create status output, got %q", out.String())
+	}
+}
+
+func TestNativeCreateUploadsLocalWeights(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("gguf weights"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Modelfile"), []byte("FROM ./model.gguf\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Unsafe:     true,
+		Args:       []string{"create", "mine", "--file", filepath.Join(dir, "Modelfile")},
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: i18n.New("en"),
+	})
+	if err != nil || code != 0 {
+		t.Fatalf("create: code=%d err=%v out=%q", code, err, out.String())
+	}
+	for _, want := range []string{"hashing model.gguf", "uploading ", ": 100% 12 B", "success"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got %q", want, out.String())
+		}
+	}
+}
+
+func TestParseCmdArgs(t *testing.T) {
+	flags, rest, err := parseCmdArgs([]string{"model", "--format", "json", "-x", "--n=3", "--", "--literal"}, flagSpec{"format": true, "x": false, "n": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.String("format") != "json" || !flags.Bool("x") || flags.String("n") != "3" {
+		t.Fatalf("unexpected flags: %v", flags)
+	}
+	if strings.Join(rest, " ") != "model --literal" {
+		t.Fatalf("unexpected positional args: %q", rest)
+	}
+
+	// Flags end where the prompt starts.
+	flags, rest, err = parseCmdArgs([]string{"model", "-x", "explain", "-rf", "--format", "json"}, flagSpec{"format": true, "x": false})
+	if err != nil || !flags.Bool("x") || flags.Has("format") {
+		t.Fatalf("unexpected flags: %v, %v", flags, err)
+	}
+	if strings.Join(rest, " ") != "model explain -rf --format json" {
+		t.Fatalf("unexpected positional args: %q", rest)
+	}
+	_, rest, err = parseCmdArgs([]string{"model", "-v is a flag", "--system=be brief"}, flagSpec{"system": true})
+	if err != nil || strings.Join(rest, "|") != "model|-v is a flag|--system=be brief" {
+		t.Fatalf("prompt starting with a dash: %q, %v", rest, err)
+	}
+
+	if _, _, err := parseCmdArgs([]string{"--nope"}, flagSpec{}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
+	if _, _, err := parseCmdArgs([]string{"--format"}, flagSpec{"format": true}); err == nil {
+		t.Fatal("expected error for missing value")
+	}
+}
+
+func TestNativePush(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	tr := i18n.New("en")
+	var out strings.Builder
+	code, err := Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Args:       []string{"push", "llama3:8b"},
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: tr,
+	})
+	if code != 2 || err == nil {
+		t.Fatalf("expected push to require unsafe, got code=%d err=%v", code, err)
+	}
+
+	out.Reset()
+	code, err = Run(context.Background(), Options{
+		Mode:       "native",
+		Host:       s.URL,
+		Unsafe:     true,
+		Args:       []string{"push", "--insecure", "llama3:8b"},
+		Stdout:     &out,
+		Stderr:     &out,
+		Translator: tr,
+	})
+	if err != nil || code != 0 {
+		t.Fatalf("push: code=%d err=%v out=%q", code, err, out.String())
+	}
+	if !strings.Contains(out.String(), "insecure") {
+		t.Fatalf("expected insecure push, got %q", out.String())
+	}
+}
+
+func TestNativeStopAndLoad(t *testing.T) {
+	var mu sync.Mutex
+	var reqs []ollamaapi.GenerateRequest
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/ps":
+			fmt.Fprint(w, `{"models":[{"name":"llama3:8b","model":"llama3:8b"},{"name":"qwen2:7b","model":"qwen2:7b"}]}`)
+		case "/api/generate":
+			var req ollamaapi.GenerateRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode: %v", err)
+			}
+			mu.Lock()
+			reqs = append(reqs, req)
+			mu.Unlock()
+			if req.Model == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error":"model 'missing' not found"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"model":%q,"response":"","done":true}`, req.Model)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	run := func(args ...string) (int, string, error) {
+		var out strings.Builder
+		code, err := Run(context.Background(), Options{
+			Mode:       "native",
+			Host:       s.URL,
+			Args:       args,
+			Stdout:     &out,
+			Stderr:     &out,
+			Translator: i18n.New("en"),
+		})
+		return code, out.String(), err
+	}
+
+	if code, out, err := run("stop", "llama3:8b"); err != nil || code != 0 || !strings.Contains(out, "Stopped model: llama3:8b") {
+		t.Fatalf("stop: code=%d err=%v out=%q", code, err, out)
+	}
+	if code, out, err := run("stop", "--all"); err != nil || code != 0 || !strings.Contains(out, "qwen2:7b") {
+		t.Fatalf("stop --all: code=%d err=%v out=%q", code, err, out)
+	}
+	if code, out, err := run("load", "llama3:8b", "--keepalive", "30m"); err != nil || code != 0 || !strings.Contains(out, "30m") {
+		t.Fatalf("load: code=%d err=%v out=%q", code, err, out)
+	}
+	if code, _, err := run("load", "missing"); code != 1 || err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("load missing: code=%d err=%v", code, err)
+	}
+
+	want := []string{"llama3:8b 0", "llama3:8b 0", "qwen2:7b 0", "llama3:8b 30m", "missing "}
+	if len(reqs) != len(want) {
+		t.Fatalf("expected %d generate requests, got %+v", len(want), reqs)
+	}
+	for i, r := range reqs {
+		if got := r.Model + " " + r.KeepAlive; got != want[i] || r.Prompt != "" || r.Stream {
+			t.Errorf("request %d: %+v, want %q", i, r, want[i])
+		}
+	}
+
+	for _, args := range [][]string{{"stop"}, {"stop", "--all", "llama3:8b"}, {"stop", "a", "b"}, {"load"}} {
+		if code, _, err := run(args...); code != 2 || err == nil {
+			t.Errorf("%v: expected usage error, got code=%d err=%v", args, code, err)
+		}
+	}
+	if code, _, err := run("load", "llama3:8b", "--keepalive", "soon"); code != 2 || err == nil {
+		t.Errorf("expected invalid duration, got code=%d err=%v", code, err)
+	}
+
+	if !NativeOnly([]string{"stop", "--all"}) || !NativeOnly([]string{"load", "m"}) || NativeOnly([]string{"stop", "m"}) {
+		t.Error("unexpected NativeOnly result for stop/load")
+	}
+}
+
 func newFakeOllamaServer(t *testing.T) *httptest.Server {
 	t.Helper()
 
+	blobs := map[string]bool{}
 	mux := http.NewServeMux()
+	mux.HandleFunc("/api/blobs/", func(w http.ResponseWriter, r *http.Request) {
+		digest := strings.TrimPrefix(r.URL.Path, "/api/blobs/")
+		switch r.Method {
+		case http.MethodHead:
+			if !blobs[digest] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPost:
+			io.Copy(io.Discard, r.Body)
+			blobs[digest] = true
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
 	mux.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprint(w, `{"version":"0.0.1"}`)
@@ -99,13 +793,68 @@ func newFakeOllamaServer(t *testing.T) *httptest.Server {
 	})
 	mux.HandleFunc("/api/show", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprint(w, `{"name":"llama3:8b"}`)
+		fmt.Fprint(w, `{"modelfile":"FROM llama3:8b\n","parameters":"num_ctx 8192","template":"{{ .Prompt }}",`+
+			`"details":{"parameter_size":"8.0B","quantization_level":"Q4_0"},"model_info":{"general.architecture":"llama"},`+
+			`"tensors":[{"name":"token_embd.weight","type":"Q4_0"}]}`)
 	})
 	mux.HandleFunc("/api/generate", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprint(w, "{\"response\":\"hi\",\"done\":false}\n")
 		fmt.Fprint(w, "{\"response\":\"!\",\"done\":true}\n")
 	})
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaapi.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"bad request"}`, http.StatusBadRequest)
+			return
+		}
+		if n := len(req.Messages); n == 0 || req.Messages[n-1].Role != "user" {
+			http.Error(w, `{"error":"last message must be from user"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"hi\"},\"done\":false}\n")
+		fmt.Fprint(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"!\"},\"done\":true}\n")
+	})
+	mux.HandleFunc("/api/embed", func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaapi.EmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"bad request"}`, http.StatusBadRequest)
+			return
+		}
+		// Encode each input's length as a one-dimensional vector.
+		resp := ollamaapi.EmbedResponse{Model: req.Model}
+		for _, in := range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float64{float64(len(in))})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/api/create", func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaapi.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.From == "" && len(req.Files) == 0) {
+			http.Error(w, `{"error":"bad request"}`, http.StatusBadRequest)
+			return
+		}
+		for _, digest := range req.Files {
+			if !blobs[digest] {
+				http.Error(w, `{"error":"missing blob"}`, http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{\"status\":\"writing manifest\"}\n")
+		fmt.Fprint(w, "{\"status\":\"success\"}\n")
+	})
+	mux.HandleFunc("/api/push", func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaapi.PushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Insecure {
+			fmt.Fprint(w, "{\"status\":\"pushing (insecure)\"}\n")
+		}
+		fmt.Fprint(w, "{\"status\":\"success\"}\n")
+	})
 	mux.HandleFunc("/api/pull", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprint(w, "{\"status\":\"pulling\",\"completed\":1,\"total\":2}\n")
@@ -114,3 +863,85 @@ func newFakeOllamaServer(t *testing.T) *httptest.Server {
 
 	return httptest.NewServer(mux)
 }
+
+func TestMultipleHosts(t *testing.T) {
+	s := newFakeOllamaServer(t)
+	defer s.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := "http://" + ln.Addr().String()
+	ln.Close()
+
+	opts := Options{
+		Mode:       "native",
+		Host:       dead,
+		Hosts:      []config.HostEntry{{URL: dead}, {URL: s.URL}},
+		Args:       []string{"list"},
+		Translator: i18n.New("en"),
+	}
+	var out strings.Builder
+	opts.Stdout, opts.Stderr = &out, &out
+	if code, err := Run(context.Background(), opts); err != nil || code != 0 {
+		t.Fatalf("list: code=%d err=%v out=%q", code, err, out.String())
+	}
+	if !strings.Contains(out.String(), "NAME") {
+		t.Fatalf("expected table header, got %q", out.String())
+	}
+
+	// Wrapper mode hands the upstream CLI the healthy host.
+	u, err := selectHost(context.Background(), opts)
+	if err != nil || u.String() != s.URL {
+		t.Fatalf("selectHost = %v, %v", u, err)
+	}
+	opts.Hosts = []config.HostEntry{{URL: dead}, {URL: dead}}
+	if _, err := selectHost(context.Background(), opts); !errors.Is(err, ollamaapi.ErrNoHealthyHost) {
+		t.Fatalf("expected ErrNoHealthyHost, got %v", err)
+	}
+}
+
+func TestStartRelay(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"version":"0.0.1"}`)
+	}))
+	defer s.Close()
+
+	host, stop, err := startRelay(Options{Host: s.URL, Headers: http.Header{"Authorization": {"Bearer secret"}}})
+	if err != nil {
+		t.Fatalf("startRelay: %v", err)
+	}
+	defer stop()
+
+	resp, err := http.Get(host + "/api/version")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "0.0.1") {
+		t.Fatalf("relay: %d %s", resp.StatusCode, body)
+	}
+
+	// Requests naming another host are refused.
+	req, _ := http.NewRequest(http.MethodGet, host+"/api/version", nil)
+	req.Host = "attacker.example:80"
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for foreign Host, got %d", resp.StatusCode)
+	}
+
+	env := setEnv([]string{"OLLAMA_HOST=http://remote:11434", "PATH=/bin"}, "OLLAMA_HOST", host)
+	if strings.Join(env, " ") != "PATH=/bin OLLAMA_HOST="+host {
+		t.Fatalf("unexpected env: %v", env)
+	}
+}
@@ -0,0 +1,97 @@
+package ollamarunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+// showFields are the single-field views of show, in the order they are listed
+// in usage and conflict messages.
+var showFields = []string{"modelfile", "parameters", "template", "system", "license"}
+
+var showFlags = flagSpec{
+	"modelfile":  false,
+	"parameters": false,
+	"template":   false,
+	"system":     false,
+	"license":    false,
+	"json":       false,
+}
+
+// runShow implements: show MODEL [--modelfile|--parameters|--template|--system|--license|--json]
+//
+// Without flags it prints a human summary like the upstream CLI.
+func runShow(ctx context.Context, client *ollamaapi.Client, args []string, opts Options, tr *i18n.Bundle) (int, error) {
+	flags, rest, err := parseCmdArgs(args, showFlags)
+	if err != nil {
+		return 2, translateFlagError(tr, err)
+	}
+	if len(rest) != 1 || strings.TrimSpace(rest[0]) == "" {
+		return 2, errors.New(tr.Sprintf("error.native.usage_show"))
+	}
+	var views []string
+	for _, f := range append(showFields, "json") {
+		if flags.Bool(f) {
+			views = append(views, f)
+		}
+	}
+	if len(views) > 1 {
+		return 2, errors.New(tr.Sprintf("error.native.show_flags_conflict", "flags", "--"+strings.Join(views, ", --")))
+	}
+
+	view := ""
+	if len(views) == 1 {
+		view = views[0]
+	}
+	if view == "json" {
+		// Print the response as the server sent it, including fields that
+		// ShowResponse doesn't cover.
+		raw, err := client.ShowRaw(ctx, strings.TrimSpace(rest[0]))
+		if err != nil {
+			return 1, err
+		}
+		var b bytes.Buffer
+		if err := json.Indent(&b, raw, "", "  "); err != nil {
+			return 1, err
+		}
+		fmt.Fprintln(opts.Stdout, b.String())
+		return 0, nil
+	}
+
+	resp, err := client.Show(ctx, strings.TrimSpace(rest[0]))
+	if err != nil {
+		return 1, err
+	}
+	var text string
+	switch view {
+	case "":
+		fmt.Fprint(opts.Stdout, ollamaapi.FormatShow(resp))
+		return 0, nil
+	case "modelfile":
+		text = resp.Modelfile
+	case "parameters":
+		text = resp.Parameters
+	case "template":
+		text = resp.Template
+	case "system":
+		text = resp.System
+	case "license":
+		text = resp.License
+	}
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(opts.Stdout, tr.Sprintf("native.show.empty", "field", view))
+		return 0, nil
+	}
+	fmt.Fprint(opts.Stdout, text)
+	if !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(opts.Stdout)
+	}
+	return 0, nil
+}
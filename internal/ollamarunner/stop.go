@@ -0,0 +1,92 @@
+package ollamarunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+var stopFlags = flagSpec{"all": false}
+
+var loadFlags = flagSpec{"keepalive": true}
+
+// runStop implements: stop MODEL | stop --all
+//
+// With --all every model listed by /api/ps is unloaded; a failure for one
+// model does not stop the others.
+func runStop(ctx context.Context, client *ollamaapi.Client, args []string, opts Options, tr *i18n.Bundle) (int, error) {
+	flags, rest, err := parseCmdArgs(args, stopFlags)
+	if err != nil {
+		return 2, translateFlagError(tr, err)
+	}
+	all := flags.Bool("all")
+	model := ""
+	if len(rest) == 1 {
+		model = strings.TrimSpace(rest[0])
+	}
+	// Exactly one of MODEL and --all.
+	if len(rest) > 1 || all == (model != "") {
+		return 2, errors.New(tr.Sprintf("error.native.usage_stop"))
+	}
+	if !all {
+		if err := client.Unload(ctx, model); err != nil {
+			return 1, err
+		}
+		fmt.Fprintln(opts.Stdout, tr.Sprintf("native.stopped", "model", model))
+		return 0, nil
+	}
+
+	procs, err := client.PS(ctx)
+	if err != nil {
+		return 1, err
+	}
+	if len(procs) == 0 {
+		fmt.Fprintln(opts.Stdout, tr.Sprintf("native.stop_none"))
+		return 0, nil
+	}
+	var errs []error
+	for _, p := range procs {
+		model := p.Model
+		if model == "" {
+			model = p.Name
+		}
+		if err := client.Unload(ctx, model); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintln(opts.Stdout, tr.Sprintf("native.stopped", "model", model))
+	}
+	if len(errs) > 0 {
+		return 1, errors.Join(errs...)
+	}
+	return 0, nil
+}
+
+// runLoad implements: load MODEL [--keepalive DURATION]
+func runLoad(ctx context.Context, client *ollamaapi.Client, args []string, opts Options, tr *i18n.Bundle) (int, error) {
+	flags, rest, err := parseCmdArgs(args, loadFlags)
+	if err != nil {
+		return 2, translateFlagError(tr, err)
+	}
+	if len(rest) != 1 || strings.TrimSpace(rest[0]) == "" {
+		return 2, errors.New(tr.Sprintf("error.native.usage_load"))
+	}
+	keepAlive, err := normalizeKeepAlive(flags.String("keepalive"))
+	if err != nil {
+		return 2, errors.New(tr.Sprintf("error.native.invalid_duration", "flag", "--keepalive", "value", flags.String("keepalive")))
+	}
+	model := strings.TrimSpace(rest[0])
+	if err := client.Load(ctx, model, keepAlive); err != nil {
+		return 1, err
+	}
+	if keepAlive != "" {
+		fmt.Fprintln(opts.Stdout, tr.Sprintf("native.loaded_for", "model", model, "keepalive", keepAlive))
+	} else {
+		fmt.Fprintln(opts.Stdout, tr.Sprintf("native.loaded", "model", model))
+	}
+	return 0, nil
+}
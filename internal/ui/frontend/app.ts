@@ -7,6 +7,7 @@ type ApiExecResponse = {
 type ConfigResponse = {
   configPath?: string;
   host?: string;
+  apiBase?: string;
   lang?: string;
   mode?: string;
   unsafe?: boolean;
@@ -15,6 +16,19 @@ type ConfigResponse = {
   selectedMode?: string;
 };
 
+type ProgressLine = {
+  status: string;
+  digest?: string;
+  total?: number;
+  completed?: number;
+  rate?: number;
+  eta?: number;
+};
+
+type PullEvent = ApiExecResponse & {
+  progress?: ProgressLine[];
+};
+
 type ModelRow = {
   name: string;
   id?: string;
@@ -58,7 +72,9 @@ const elCfgPath = qs<HTMLElement>("#cfgPath");
 
 const elRunModel = qs<HTMLInputElement>("#runModel");
 const elPrompt = qs<HTMLTextAreaElement>("#prompt");
+const elRunImages = qs<HTMLInputElement>("#runImages");
 const elPullModel = qs<HTMLInputElement>("#pullModel");
+const elPullProgress = qs<HTMLDivElement>("#pullProgress");
 
 const btnTheme = qs<HTMLButtonElement>("#btnTheme");
 const btnList = qs<HTMLButtonElement>("#btnList");
@@ -111,6 +127,39 @@ async function apiPost(path: string, body: any = {}): Promise<ApiExecResponse> {
   return data;
 }
 
+async function apiPostStream(path: string, body: any, onEvent: (ev: PullEvent) => void): Promise<PullEvent> {
+  const res = await fetch(`${path}?t=${encodeURIComponent(token)}`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json", "X-Token": token },
+    body: JSON.stringify(body)
+  });
+  if (!res.ok || !res.body) {
+    const data = (await res.json().catch(() => ({}))) as ApiExecResponse;
+    throw new Error(data.error || `HTTP ${res.status}`);
+  }
+  // The server sends one JSON object per line; the last one carries the exit code.
+  const reader = res.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  let last: PullEvent = {};
+  for (;;) {
+    const { done, value } = await reader.read();
+    buf += decoder.decode(value || new Uint8Array(), { stream: !done });
+    let nl = buf.indexOf("\n");
+    while (nl >= 0) {
+      const line = buf.slice(0, nl).trim();
+      buf = buf.slice(nl + 1);
+      if (line) {
+        last = JSON.parse(line) as PullEvent;
+        onEvent(last);
+      }
+      nl = buf.indexOf("\n");
+    }
+    if (done) break;
+  }
+  return last;
+}
+
 async function apiGetConfig(): Promise<ConfigResponse> {
   const res = await fetch(`/api/config?t=${encodeURIComponent(token)}`, {
     headers: { "X-Token": token }
@@ -135,6 +184,59 @@ function showOutput(label: string, data: ApiExecResponse) {
   if (next) elOut.textContent = next;
 }
 
+function fmtBytes(n: number): string {
+  const units = ["B", "KB", "MB", "GB", "TB"];
+  let v = n;
+  let i = 0;
+  while (v >= 1024 && i < units.length - 1) {
+    v /= 1024;
+    i++;
+  }
+  return i === 0 ? `${Math.round(v)} B` : `${v.toFixed(1)} ${units[i]}`;
+}
+
+function fmtEta(ns: number): string {
+  const s = Math.round(ns / 1e9);
+  if (s < 60) return `${s}s`;
+  const m = Math.floor(s / 60);
+  if (m < 60) return `${m}m${s % 60}s`;
+  return `${Math.floor(m / 60)}h${m % 60}m`;
+}
+
+function renderPullProgress(lines: ProgressLine[]) {
+  elPullProgress.innerHTML = "";
+  elPullProgress.hidden = !lines.length;
+  for (const l of lines) {
+    const row = document.createElement("div");
+    row.className = "progress__row";
+
+    const label = document.createElement("div");
+    label.className = "progress__label mono";
+    label.textContent = l.status;
+    row.appendChild(label);
+
+    if (l.total) {
+      const completed = l.completed || 0;
+      const bar = document.createElement("progress");
+      bar.max = l.total;
+      bar.value = completed;
+      row.appendChild(bar);
+
+      const parts = [
+        `${Math.min(100, Math.floor((completed * 100) / l.total))}%`,
+        `${fmtBytes(completed)}/${fmtBytes(l.total)}`
+      ];
+      if (completed < l.total && l.rate) parts.push(`${fmtBytes(l.rate)}/s`);
+      if (completed < l.total && l.eta) parts.push(`ETA ${fmtEta(l.eta)}`);
+      const meta = document.createElement("div");
+      meta.className = "mono";
+      meta.textContent = parts.join(" · ");
+      row.appendChild(meta);
+    }
+    elPullProgress.appendChild(row);
+  }
+}
+
 function uniq(list: string[]): string[] {
   const out: string[] = [];
   const seen = new Set<string>();
@@ -342,6 +444,7 @@ async function loadConfig() {
   elNoProxyAuto.checked = !!data.noProxyAuto;
 
   elChipHost.textContent = data.host ? `host: ${data.host}` : "host: —";
+  elChipHost.title = data.apiBase || "";
   elChipMode.textContent = data.selectedMode ? `mode: ${data.selectedMode}` : `mode: ${data.mode || "auto"}`;
 }
 
@@ -359,6 +462,23 @@ async function refreshModels() {
   }
 }
 
+function readFileBase64(file: File): Promise<string> {
+  return new Promise((resolve, reject) => {
+    const reader = new FileReader();
+    reader.onload = () => {
+      // Strip the "data:<type>;base64," prefix; the server sniffs the type itself.
+      const url = String(reader.result || "");
+      resolve(url.slice(url.indexOf(",") + 1));
+    };
+    reader.onerror = () => reject(reader.error || new Error(`Failed to read ${file.name}`));
+    reader.readAsDataURL(file);
+  });
+}
+
+async function runImages(): Promise<string[]> {
+  return Promise.all(Array.from(elRunImages.files || []).map(readFileBase64));
+}
+
 async function runPrompt() {
   const model = elRunModel.value.trim();
   const prompt = elPrompt.value;
@@ -367,7 +487,8 @@ async function runPrompt() {
   try {
     localStorage.setItem("ollama-remote.ui.lastModel", model);
     localStorage.setItem("ollama-remote.ui.lastPrompt", prompt);
-    const data = await apiPost("/api/run", { model, prompt });
+    const images = await runImages();
+    const data = await apiPost("/api/run", { model, prompt, images });
     showOutput("run", data);
   } finally {
     setBusy(false);
@@ -380,7 +501,8 @@ async function pullModel() {
   toast(msgWorking);
   try {
     localStorage.setItem("ollama-remote.ui.lastModel", model);
-    const data = await apiPost("/api/pull", { model });
+    renderPullProgress([]);
+    const data = await apiPostStream("/api/pull", { model }, (ev) => renderPullProgress(ev.progress || []));
     showOutput("pull", data);
   } finally {
     setBusy(false);
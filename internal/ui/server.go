@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"embed"
@@ -10,10 +11,15 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"math"
 	"net"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +27,7 @@ import (
 	"cli_ollama_server/internal/config"
 	"cli_ollama_server/internal/execollama"
 	"cli_ollama_server/internal/i18n"
+	"cli_ollama_server/internal/ollamaapi"
 	"cli_ollama_server/internal/ollamarunner"
 )
 
@@ -32,6 +39,13 @@ var staticFS embed.FS
 // ShutdownTimeout is the maximum duration allowed for graceful server shutdown.
 const ShutdownTimeout = 5 * time.Second
 
+const (
+	// maxRunImages caps the number of images attached from the run panel.
+	maxRunImages = 4
+	// maxRunBodySize bounds /api/run requests: base64 images plus the prompt.
+	maxRunBodySize = maxRunImages*(ollamaapi.MaxImageSize/3+1)*4 + 1<<20
+)
+
 type Server struct {
 	Listener   net.Listener
 	Translator *i18n.Bundle
@@ -179,6 +193,8 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		"{{UI_MODELS_HINT}}":            tr.Sprintf("ui.models.hint"),
 		"{{UI_HINT_RUN_SHORTCUT}}":      tr.Sprintf("ui.hint.run_shortcut"),
 		"{{UI_HINT_PULL_UNSAFE}}":       tr.Sprintf("ui.hint.pull_unsafe"),
+		"{{UI_LABEL_IMAGES}}":           tr.Sprintf("ui.label.images"),
+		"{{UI_HINT_IMAGES}}":            tr.Sprintf("ui.hint.images", "max", strconv.Itoa(maxRunImages)),
 		"{{UI_LABEL_CONFIG_PATH}}":      tr.Sprintf("ui.label.config_path"),
 		"{{UI_LABEL_MODE}}":             tr.Sprintf("ui.label.mode"),
 		"{{UI_MODE_AUTO}}":              tr.Sprintf("ui.mode.auto"),
@@ -231,14 +247,199 @@ func (s *Server) handlePull(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("ui.error.model_required"))
 		return
 	}
-	out, code, err := s.runOllama([]string{"pull", req.Model})
-	respondExec(w, out, code, err)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	stream := newPullStream(w)
+	code, err := s.runOllamaTo([]string{"pull", req.Model}, stream, stream.stderr)
+	stream.finish(s.Effective.Redact(stream.stderr.lastLine), code, err)
+}
+
+// pullStreamInterval throttles progress events sent to the UI.
+const pullStreamInterval = 250 * time.Millisecond
+
+// ansiRE matches terminal control sequences emitted by the upstream CLI.
+var ansiRE = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// pullStream aggregates pull progress and sends it to the UI as NDJSON:
+// {"progress":[...]} snapshots while the pull runs, then one final object
+// shaped like respondExec with the last snapshot attached.
+//
+// Native pulls report chunks; in wrapper mode the upstream CLI's stdout and
+// its stderr, where it draws the progress bars, are both parsed as status
+// lines.
+type pullStream struct {
+	*ollamaapi.Progress
+	stdout *pullPipe
+	stderr *pullPipe
+
+	mu   sync.Mutex // guards w and last; both pipes are written concurrently
+	w    http.ResponseWriter
+	last time.Time
+}
+
+func newPullStream(w http.ResponseWriter) *pullStream {
+	p := &pullStream{Progress: ollamaapi.NewProgress(nil, false), w: w}
+	p.stdout = &pullPipe{s: p}
+	p.stderr = &pullPipe{s: p}
+	return p
+}
+
+func (p *pullStream) Report(c ollamaapi.PullChunk) error {
+	if err := p.Progress.Report(c); err != nil {
+		return err
+	}
+	return p.emit()
+}
+
+// Write accepts the upstream CLI's stdout.
+func (p *pullStream) Write(b []byte) (int, error) {
+	return p.stdout.Write(b)
+}
+
+func (p *pullStream) emit() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if time.Since(p.last) < pullStreamInterval {
+		return nil
+	}
+	p.last = time.Now()
+	return p.send(map[string]any{"progress": p.Snapshot()})
+}
+
+// finish sends the final object. errText is the last line of stderr, used
+// as the error when the command failed without one of its own.
+func (p *pullStream) finish(errText string, code int, err error) {
+	p.stdout.flush()
+	p.stderr.flush()
+	p.Close()
+	lines := p.Snapshot()
+	var out strings.Builder
+	for _, l := range lines {
+		out.WriteString(l.String())
+		out.WriteString("\n")
+	}
+	resp := map[string]any{"progress": lines, "output": out.String(), "exitCode": code}
+	if err != nil {
+		resp["error"] = err.Error()
+	} else if code != 0 && errText != "" {
+		resp["error"] = errText
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.send(resp)
+}
+
+// send writes one event; the caller holds p.mu.
+func (p *pullStream) send(v any) error {
+	if err := json.NewEncoder(p.w).Encode(v); err != nil {
+		return err
+	}
+	if f, ok := p.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// pullPipe turns one output stream of the upstream CLI into status lines:
+// redraws ("\r") end a line and terminal control sequences are dropped.
+// Each pipe buffers its own partial line so stdout and stderr do not mix.
+type pullPipe struct {
+	s        *pullStream
+	partial  []byte
+	lastLine string // last non-empty line, as plain text
+}
+
+func (p *pullPipe) Write(b []byte) (int, error) {
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexAny(p.partial, "\r\n")
+		if i < 0 {
+			break
+		}
+		line := string(p.partial[:i])
+		p.partial = p.partial[i+1:]
+		if err := p.line(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush passes on a final line without a line break.
+func (p *pullPipe) flush() {
+	if len(p.partial) > 0 {
+		line := string(p.partial)
+		p.partial = nil
+		p.line(line)
+	}
+}
+
+func (p *pullPipe) line(raw string) error {
+	line := strings.TrimSpace(ansiRE.ReplaceAllString(raw, ""))
+	if line == "" {
+		return nil
+	}
+	p.lastLine = line
+	if c, ok := parseUpstreamLayer(line); ok {
+		return p.s.Report(c)
+	}
+	// Spinners would make each redraw of a status look new.
+	line = strings.TrimRightFunc(line, func(r rune) bool { return r >= 0x2800 && r <= 0x28ff || r == ' ' })
+	if _, err := p.s.Progress.Write([]byte(line + "\n")); err != nil {
+		return err
+	}
+	return p.s.emit()
+}
+
+// upstreamLayerRE matches a layer bar of the upstream CLI:
+//
+//	pulling 6a0746a1ec1a...  45% ▕███████         ▏ 2.1 GB/4.7 GB   50 MB/s   52s
+//	pulling 6a0746a1ec1a... 100% ▕████████████████▏ 4.7 GB
+var upstreamLayerRE = regexp.MustCompile(`^(\w+) ([0-9a-f]{12})\.*\s+\d+%.*▏\s*([\d.]+ ?[KMGT]?B)(?:/([\d.]+ ?[KMGT]?B))?`)
+
+// parseUpstreamLayer turns a layer bar into the chunk it was drawn from, so
+// each layer keeps one entry whose progress is updated.
+func parseUpstreamLayer(line string) (ollamaapi.PullChunk, bool) {
+	m := upstreamLayerRE.FindStringSubmatch(line)
+	if m == nil {
+		return ollamaapi.PullChunk{}, false
+	}
+	completed, ok := parseHumanBytes(m[3])
+	if !ok {
+		return ollamaapi.PullChunk{}, false
+	}
+	total := completed
+	if m[4] != "" {
+		if total, ok = parseHumanBytes(m[4]); !ok {
+			return ollamaapi.PullChunk{}, false
+		}
+	}
+	return ollamaapi.PullChunk{Status: m[1] + " " + m[2], Digest: m[2], Total: total, Completed: completed}, true
+}
+
+// parseHumanBytes parses sizes such as "4.7 GB" in the upstream CLI's
+// decimal units.
+func parseHumanBytes(v string) (int64, bool) {
+	v = strings.TrimSuffix(strings.ReplaceAll(v, " ", ""), "B")
+	mult := 1.0
+	if n := len(v); n > 0 {
+		if i := strings.IndexByte("KMGT", v[n-1]); i >= 0 {
+			mult = math.Pow(1000, float64(i+1))
+			v = v[:n-1]
+		}
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int64(f * mult), true
 }
 
 func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRunBodySize)
 	var req struct {
-		Model  string `json:"model"`
-		Prompt string `json:"prompt"`
+		Model  string   `json:"model"`
+		Prompt string   `json:"prompt"`
+		Images []string `json:"images"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("ui.error.bad_request"))
@@ -249,24 +450,85 @@ func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("ui.error.model_required"))
 		return
 	}
+	if len(req.Images) > maxRunImages {
+		respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("ui.error.too_many_images", "max", strconv.Itoa(maxRunImages)))
+		return
+	}
+
+	args := []string{"run", req.Model}
+	prompt := req.Prompt
+	if len(req.Images) > 0 {
+		dir, err := os.MkdirTemp("", "ollama-remote-ui-")
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		defer os.RemoveAll(dir)
+		paths, err := writeImages(dir, req.Images)
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("ui.error.image_invalid", "error", err.Error()))
+			return
+		}
+		if s.selectedMode() == "native" {
+			for _, p := range paths {
+				args = append(args, "--image", p)
+			}
+		} else {
+			// The upstream CLI picks up image paths mentioned in the prompt.
+			prompt = strings.TrimSpace(prompt + " " + strings.Join(paths, " "))
+		}
+	}
 	// Use "--" to ensure prompts that start with '-' are not treated as flags by the wrapper CLI.
-	out, code, err := s.runOllama([]string{"run", req.Model, "--", req.Prompt})
+	out, code, err := s.runOllama(append(args, "--", prompt))
 	respondExec(w, out, code, err)
 }
 
-func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+// writeImages validates base64 images from the run panel and writes them to
+// dir so they can be passed to the CLI by path.
+func writeImages(dir string, images []string) ([]string, error) {
+	paths := make([]string, 0, len(images))
+	for i, b64 := range images {
+		data, err := ollamaapi.DecodeImage(b64)
+		if err != nil {
+			return nil, fmt.Errorf("#%d: %w", i+1, err)
+		}
+		ext := ".png"
+		switch http.DetectContentType(data) {
+		case "image/jpeg":
+			ext = ".jpg"
+		case "image/webp":
+			ext = ".webp"
+		}
+		p := filepath.Join(dir, fmt.Sprintf("image-%d%s", i+1, ext))
+		if err := os.WriteFile(p, data, 0o600); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// selectedMode resolves "auto" to the mode the runner will actually use.
+func (s *Server) selectedMode() string {
 	selected := s.Effective.Mode
 	if selected == "auto" {
-		if _, err := execollama.ResolveExecutable(s.Effective.OllamaExe); err == nil {
+		if s.Effective.Backend == "openai" {
+			selected = "native"
+		} else if _, err := execollama.ResolveExecutable(s.Effective.OllamaExe); err == nil {
 			selected = "wrapper"
 		} else {
 			selected = "native"
 		}
 	}
+	return selected
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	selected := s.selectedMode()
 
 	resp := map[string]any{
 		"configPath":   s.ConfigPath,
-		"host":         s.Effective.Host,
+		"host":         s.Effective.HostList(),
 		"lang":         s.Translator.Lang(),
 		"mode":         s.Effective.Mode,
 		"unsafe":       s.Effective.Unsafe,
@@ -274,6 +536,10 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 		"ollamaExe":    s.Effective.OllamaExe,
 		"selectedMode": selected,
 	}
+	// The API base makes a reverse-proxy path prefix visible in the UI.
+	if u, err := config.ParseHostURL(s.Effective.Host); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		resp["apiBase"] = u.JoinPath("api").String()
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
@@ -296,15 +562,21 @@ func (s *Server) handleConfigSet(w http.ResponseWriter, r *http.Request) {
 		if v == "" {
 			v = "http://127.0.0.1:11434"
 		}
-		if _, herr := config.ParseHostURL(v); herr != nil {
-			respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("error.invalid_host", "host", v, "error", herr.Error()))
+		hosts := config.SplitHosts(v)
+		if host, herr := config.CheckHosts(hosts); herr != nil {
+			respondErr(w, http.StatusBadRequest, s.Translator.Sprintf("error.invalid_host", "host", host, "error", herr.Error()))
 			return
 		}
-		if err := config.SetUserConfig(s.ConfigPath, "host", v); err != nil {
+		for i, h := range hosts {
+			hosts[i].URL, _ = config.NormalizeHost(h.URL)
+		}
+		next := s.Effective
+		next.Hosts, next.Host = hosts, hosts[0].URL
+		if err := config.SetUserConfig(s.ConfigPath, "host", next.HostList()); err != nil {
 			respondErr(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		s.Effective.Host = v
+		s.Effective = next
 	}
 	if req.Lang != nil {
 		v := strings.TrimSpace(*req.Lang)
@@ -368,25 +640,58 @@ func (s *Server) handleConfigSet(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) runOllama(args []string) (string, int, error) {
-	env, _, _ := config.BuildChildEnv(config.ChildEnvOptions{Existing: s.BaseEnv, Effective: s.Effective})
 	var b strings.Builder
+	code, err := s.runOllamaTo(args, &b, &b)
+	return s.Effective.Redact(b.String()), code, err
+}
+
+func (s *Server) runOllamaTo(args []string, stdout, stderr io.Writer) (int, error) {
+	tlsCfg, err := s.Effective.TLSConfig()
+	if err != nil {
+		return 2, errors.New(s.Translator.Sprintf("error.invalid_tls", "error", err.Error()))
+	}
+	proxyURL, err := s.Effective.ProxyURL()
+	if err != nil {
+		return 2, errors.New(s.Translator.Sprintf("error.invalid_proxy", "error", err.Error()))
+	}
+	timeouts, err := s.Effective.Timeouts()
+	if err != nil {
+		return 2, errors.New(s.Translator.Sprintf("error.invalid_timeout", "error", err.Error()))
+	}
+	sshCfg, err := s.Effective.SSHConfig()
+	if err != nil {
+		return 2, errors.New(s.Translator.Sprintf("error.invalid_ssh", "error", err.Error()))
+	}
+	env, _, _ := config.BuildChildEnv(config.ChildEnvOptions{Existing: s.BaseEnv, Effective: s.Effective})
 	code, err := ollamarunner.Run(context.Background(), ollamarunner.Options{
 		Mode:        s.Effective.Mode,
 		Host:        s.Effective.Host,
 		OllamaExe:   s.Effective.OllamaExe,
 		NoProxyAuto: s.Effective.NoProxyAuto,
 		Unsafe:      s.Effective.Unsafe,
+		Hosts:       s.Effective.Hosts,
+		Balance:     s.Effective.Balance,
+		Headers:     s.Effective.RequestHeaders(),
+		TLS:         tlsCfg,
+		Proxy:       proxyURL,
+		NoProxy:     s.Effective.NoProxy,
+		SSH:         ollamaapi.SSHConfig(sshCfg),
+		Timeouts:    ollamaapi.Timeouts(timeouts),
+		Backend:     s.Effective.Backend,
 		Env:         env,
 		Args:        args,
-		Stdout:      &b,
-		Stderr:      &b,
+		Stdout:      stdout,
+		Stderr:      stderr,
 		Stdin:       strings.NewReader(""),
 		Translator:  s.Translator,
 	})
 	if err != nil && errors.Is(err, execollama.ErrNotFound) {
-		return b.String(), code, execollama.ErrNotFound
+		return code, execollama.ErrNotFound
+	}
+	if err != nil {
+		return code, errors.New(s.Effective.Redact(err.Error()))
 	}
-	return b.String(), code, err
+	return code, nil
 }
 
 func respondExec(w http.ResponseWriter, out string, code int, err error) {
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cli_ollama_server/internal/ollamaapi"
+)
+
+func TestPullStreamUpstreamStderr(t *testing.T) {
+	rec := httptest.NewRecorder()
+	stream := newPullStream(rec)
+
+	// The upstream CLI redraws its bars in place on stderr.
+	stderr := []string{
+		"\x1b[?25lpulling manifest ⠋\x1b[K\r",
+		"pulling manifest ⠙\x1b[K\rpulling manifest \x1b[K\n",
+		"pulling 6a0746a1ec1a...   1% ▕                ▏  47 MB/4.7 GB   50 MB/s  1m33s\x1b[K\r",
+		"pulling 6a0746a1ec1a...  45% ▕███████         ▏ 2.1 GB/4.7 GB   50 MB/s   52s\x1b[K\r",
+		"pulling 6a0746a1ec1a... 100% ▕████████████████▏ 4.7 GB\x1b[K\n",
+		"Error: max retries exceeded\x1b[?25h\n",
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		stream.Write([]byte("writing manifest\n"))
+	}()
+	for _, s := range stderr {
+		stream.stderr.Write([]byte(s))
+	}
+	wg.Wait()
+	stream.finish(stream.stderr.lastLine, 1, nil)
+
+	var events []map[string]json.RawMessage
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		var ev map[string]json.RawMessage
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("bad event %q: %v", sc.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) < 2 {
+		t.Fatalf("expected progress events before the result, got %d events", len(events))
+	}
+	if _, ok := events[0]["progress"]; !ok || events[0]["exitCode"] != nil {
+		t.Fatalf("first event is not a progress event: %v", events[0])
+	}
+
+	final := events[len(events)-1]
+	var lines []ollamaapi.ProgressLine
+	if err := json.Unmarshal(final["progress"], &lines); err != nil {
+		t.Fatal(err)
+	}
+	var layers int
+	for _, l := range lines {
+		if l.Digest != "" {
+			layers++
+			if !l.Done() || l.Total != 4_700_000_000 {
+				t.Errorf("layer not complete: %+v", l)
+			}
+		}
+	}
+	if layers != 1 || len(lines) != 4 {
+		t.Errorf("expected manifest, layer, error and writing statuses, got %+v", lines)
+	}
+	var output, errText string
+	json.Unmarshal(final["output"], &output)
+	json.Unmarshal(final["error"], &errText)
+	if strings.ContainsAny(output, "\x1b\r") {
+		t.Errorf("output keeps terminal sequences: %q", output)
+	}
+	if errText != "Error: max retries exceeded" {
+		t.Errorf("error = %q", errText)
+	}
+}
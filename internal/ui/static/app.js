@@ -28,7 +28,9 @@ var elNoProxyAuto = qs("#noProxyAuto");
 var elCfgPath = qs("#cfgPath");
 var elRunModel = qs("#runModel");
 var elPrompt = qs("#prompt");
+var elRunImages = qs("#runImages");
 var elPullModel = qs("#pullModel");
+var elPullProgress = qs("#pullProgress");
 var btnTheme = qs("#btnTheme");
 var btnList = qs("#btnList");
 var btnRun = qs("#btnRun");
@@ -80,6 +82,37 @@ async function apiPost(path, body = {}) {
   if (!res.ok) throw new Error(data.error || `HTTP ${res.status}`);
   return data;
 }
+async function apiPostStream(path, body, onEvent) {
+  const res = await fetch(`${path}?t=${encodeURIComponent(token)}`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json", "X-Token": token },
+    body: JSON.stringify(body)
+  });
+  if (!res.ok || !res.body) {
+    const data = await res.json().catch(() => ({}));
+    throw new Error(data.error || `HTTP ${res.status}`);
+  }
+  const reader = res.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  let last = {};
+  for (; ; ) {
+    const { done, value } = await reader.read();
+    buf += decoder.decode(value || new Uint8Array(), { stream: !done });
+    let nl = buf.indexOf("\n");
+    while (nl >= 0) {
+      const line = buf.slice(0, nl).trim();
+      buf = buf.slice(nl + 1);
+      if (line) {
+        last = JSON.parse(line);
+        onEvent(last);
+      }
+      nl = buf.indexOf("\n");
+    }
+    if (done) break;
+  }
+  return last;
+}
 async function apiGetConfig() {
   const res = await fetch(`/api/config?t=${encodeURIComponent(token)}`, {
     headers: { "X-Token": token }
@@ -101,6 +134,53 @@ function showOutput(label, data) {
   const next = formatOutput(label, data).trimEnd();
   if (next) elOut.textContent = next;
 }
+function fmtBytes(n) {
+  const units = ["B", "KB", "MB", "GB", "TB"];
+  let v = n;
+  let i = 0;
+  while (v >= 1024 && i < units.length - 1) {
+    v /= 1024;
+    i++;
+  }
+  return i === 0 ? `${Math.round(v)} B` : `${v.toFixed(1)} ${units[i]}`;
+}
+function fmtEta(ns) {
+  const s = Math.round(ns / 1e9);
+  if (s < 60) return `${s}s`;
+  const m = Math.floor(s / 60);
+  if (m < 60) return `${m}m${s % 60}s`;
+  return `${Math.floor(m / 60)}h${m % 60}m`;
+}
+function renderPullProgress(lines) {
+  elPullProgress.innerHTML = "";
+  elPullProgress.hidden = !lines.length;
+  for (const l of lines) {
+    const row = document.createElement("div");
+    row.className = "progress__row";
+    const label = document.createElement("div");
+    label.className = "progress__label mono";
+    label.textContent = l.status;
+    row.appendChild(label);
+    if (l.total) {
+      const completed = l.completed || 0;
+      const bar = document.createElement("progress");
+      bar.max = l.total;
+      bar.value = completed;
+      row.appendChild(bar);
+      const parts = [
+        `${Math.min(100, Math.floor(completed * 100 / l.total))}%`,
+        `${fmtBytes(completed)}/${fmtBytes(l.total)}`
+      ];
+      if (completed < l.total && l.rate) parts.push(`${fmtBytes(l.rate)}/s`);
+      if (completed < l.total && l.eta) parts.push(`ETA ${fmtEta(l.eta)}`);
+      const meta = document.createElement("div");
+      meta.className = "mono";
+      meta.textContent = parts.join(" \xB7 ");
+      row.appendChild(meta);
+    }
+    elPullProgress.appendChild(row);
+  }
+}
 function uniq(list) {
   const out = [];
   const seen = /* @__PURE__ */ new Set();
@@ -270,6 +350,7 @@ async function loadConfig() {
   elUnsafe.checked = !!data.unsafe;
   elNoProxyAuto.checked = !!data.noProxyAuto;
   elChipHost.textContent = data.host ? `host: ${data.host}` : "host: \u2014";
+  elChipHost.title = data.apiBase || "";
   elChipMode.textContent = data.selectedMode ? `mode: ${data.selectedMode}` : `mode: ${data.mode || "auto"}`;
 }
 async function refreshModels() {
@@ -285,6 +366,20 @@ async function refreshModels() {
     setBusy(false);
   }
 }
+function readFileBase64(file) {
+  return new Promise((resolve, reject) => {
+    const reader = new FileReader();
+    reader.onload = () => {
+      const url = String(reader.result || "");
+      resolve(url.slice(url.indexOf(",") + 1));
+    };
+    reader.onerror = () => reject(reader.error || new Error(`Failed to read ${file.name}`));
+    reader.readAsDataURL(file);
+  });
+}
+async function runImages() {
+  return Promise.all(Array.from(elRunImages.files || []).map(readFileBase64));
+}
 async function runPrompt() {
   const model = elRunModel.value.trim();
   const prompt = elPrompt.value;
@@ -293,7 +388,8 @@ async function runPrompt() {
   try {
     localStorage.setItem("ollama-remote.ui.lastModel", model);
     localStorage.setItem("ollama-remote.ui.lastPrompt", prompt);
-    const data = await apiPost("/api/run", { model, prompt });
+    const images = await runImages();
+    const data = await apiPost("/api/run", { model, prompt, images });
     showOutput("run", data);
   } finally {
     setBusy(false);
@@ -305,7 +401,8 @@ async function pullModel() {
   toast(msgWorking);
   try {
     localStorage.setItem("ollama-remote.ui.lastModel", model);
-    const data = await apiPost("/api/pull", { model });
+    renderPullProgress([]);
+    const data = await apiPostStream("/api/pull", { model }, (ev) => renderPullProgress(ev.progress || []));
     showOutput("pull", data);
   } finally {
     setBusy(false);